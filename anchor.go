@@ -0,0 +1,231 @@
+package arkbuilders
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// AnchorPolicy selects what, if any, CPFP fee-bump handle
+// BuildCommitmentTx, BuildForfeitTx, and each VTXO tree node's spend
+// transaction append as their trailing output.
+type AnchorPolicy int
+
+const (
+	// AnchorNone appends no anchor output: the transaction must carry
+	// its own sufficient fee rate. Zero value, so params structs that
+	// never set an anchor policy keep pre-AnchorPolicy behavior.
+	AnchorNone AnchorPolicy = iota
+	// AnchorEphemeral appends a zero-value P2A (BIP-431) output anyone
+	// can spend, exempt from the dust rule for a single such output per
+	// TRUC transaction.
+	AnchorEphemeral
+	// AnchorKeyed appends a Taproot output to a caller-supplied BumpKey
+	// instead, for callers that want the CPFP handle restricted to a
+	// specific key rather than spendable by anyone watching the mempool.
+	AnchorKeyed
+)
+
+// EphemeralAnchorAmount is the value of an ephemeral (Pay-to-Anchor)
+// output: zero, since Bitcoin Core 28's policy exempts a single such
+// output per TRUC transaction from the dust rule, in exchange for the
+// anchor's entire purpose being a handle for a CPFP child.
+const EphemeralAnchorAmount = 0
+
+// ephemeralAnchorScript is OP_1 <0x4e73>, the P2A template Bitcoin Core
+// 28 recognizes as an ephemeral anchor eligible for the dust exemption.
+var ephemeralAnchorScript = []byte{txscript.OP_1, 0x02, 0x4e, 0x73}
+
+// buildEphemeralAnchorOutput returns the zero-value P2A output appended
+// to TRUC transactions that opt into AnchorEphemeral. It is always
+// appended last, after any memo, so it never disturbs the sorted
+// batch/connector/change ordering the rest of the package relies on, and
+// its value and vbytes are excluded from the parent's own fee
+// calculation since a CPFP child pays for it instead.
+func buildEphemeralAnchorOutput() *wire.TxOut {
+	return wire.NewTxOut(EphemeralAnchorAmount, ephemeralAnchorScript)
+}
+
+// buildAnchorOutput returns the trailing anchor output for policy, or
+// nil for AnchorNone. AnchorKeyed's output carries DustLimit rather than
+// EphemeralAnchorAmount's zero: unlike P2A, a keyed Taproot output gets
+// no dust-rule exemption, so it must be a real, spendable amount.
+func buildAnchorOutput(policy AnchorPolicy, bumpKey *btcec.PublicKey) (*wire.TxOut, error) {
+	switch policy {
+	case AnchorNone:
+		return nil, nil
+	case AnchorEphemeral:
+		return buildEphemeralAnchorOutput(), nil
+	case AnchorKeyed:
+		if bumpKey == nil {
+			return nil, errors.New("bump key is required for a keyed anchor")
+		}
+		script, err := BuildCheckSigScript(bumpKey)
+		if err != nil {
+			return nil, err
+		}
+		taprootScript, err := CreateTaprootScript(nil, [][]byte{script})
+		if err != nil {
+			return nil, err
+		}
+		return wire.NewTxOut(DustLimit, taprootScript), nil
+	default:
+		return nil, errors.New("unknown anchor policy")
+	}
+}
+
+// effectiveAnchorPolicy resolves policy plus a *TxParams struct's legacy
+// UseEphemeralAnchor bool into a single AnchorPolicy: an explicit policy
+// always wins, so callers can migrate to AnchorPolicy at their own pace
+// without UseEphemeralAnchor silently overriding it.
+func effectiveAnchorPolicy(policy AnchorPolicy, useEphemeralAnchor bool) AnchorPolicy {
+	if policy != AnchorNone {
+		return policy
+	}
+	if useEphemeralAnchor {
+		return AnchorEphemeral
+	}
+	return AnchorNone
+}
+
+// anchorFeeRate returns the fee rate BuildCommitmentTx/BuildForfeitTx use
+// to size their own weight when policy is active, deferring the
+// package's real fee to the CPFP child that spends the anchor: zero for
+// an ephemeral anchor (any wallet can bump it for free), or MinFeeRate
+// for a keyed one (the parent still needs to relay on its own). Returns
+// feeRate unchanged for AnchorNone.
+func anchorFeeRate(policy AnchorPolicy, feeRate int64) int64 {
+	switch policy {
+	case AnchorEphemeral:
+		return 0
+	case AnchorKeyed:
+		return MinFeeRate
+	default:
+		return feeRate
+	}
+}
+
+// BuildAnchorSpend builds the CPFP child transaction that spends
+// parentTx's ephemeral anchor output (at anchorIndex) to pay for the
+// whole TRUC package at feeRate. The anchor itself carries no value, so
+// the returned transaction has a single input (the anchor, at
+// wire.MaxTxInSequenceNum so it stays replaceable) and no outputs;
+// callers fund childFee from their own wallet inputs before broadcasting
+// the package, exactly as BuildBoardingTx/BuildCommitmentTx callers
+// provide their own FundingUTXO/OperatorUTXOs.
+func BuildAnchorSpend(parentTx *wire.MsgTx, anchorIndex int, feeRate, childFee int64) (*wire.MsgTx, error) {
+	if parentTx == nil {
+		return nil, errors.New("parent transaction is required")
+	}
+	if anchorIndex < 0 || anchorIndex >= len(parentTx.TxOut) {
+		return nil, errors.New("anchor index out of range")
+	}
+	if !bytes.Equal(parentTx.TxOut[anchorIndex].PkScript, ephemeralAnchorScript) {
+		return nil, errors.New("output at anchorIndex is not an ephemeral anchor")
+	}
+	if feeRate < MinFeeRate {
+		return nil, errors.New("fee rate must be positive")
+	}
+	if childFee <= 0 {
+		return nil, errors.New("child fee must be positive")
+	}
+
+	tx := newDeterministicTx(TxVersionTRUC, 0)
+
+	parentHash := parentTx.TxHash()
+	anchorIn := wire.NewTxIn(wire.NewOutPoint(&parentHash, uint32(anchorIndex)), nil, nil)
+	anchorIn.Sequence = wire.MaxTxInSequenceNum
+	tx.AddTxIn(anchorIn)
+
+	// The anchor alone never funds the package; childFee must at least
+	// cover this minimal child's own weight at feeRate.
+	estimatedSize := estimateTxSize(tx, 1, 66)
+	if childFee < estimatedSize*feeRate {
+		return nil, errors.New("child fee does not cover the child's own fee rate")
+	}
+
+	return tx, nil
+}
+
+// BuildBumpTx builds a CPFP child spending parentTx's anchor output (at
+// anchorIndex, either AnchorEphemeral or AnchorKeyed) plus spendUTXOs,
+// computing the fee itself rather than taking a caller-supplied amount
+// like BuildAnchorSpend does: the child is sized to cover targetFeeRate's
+// worth of the whole package (parent plus child) on its own, treating
+// the parent's own fee as zero — exact for an AnchorEphemeral parent
+// (built at rate 0), and a safe overestimate for an AnchorKeyed one
+// (built at MinFeeRate), since overpaying the package fee never yields
+// an under-funded broadcast. Any leftover above dust is paid to
+// changeAddr.
+func BuildBumpTx(parent *wire.MsgTx, anchorIndex uint32, spendUTXOs []*UTXO, targetFeeRate int64, changeAddr string) (*wire.MsgTx, error) {
+	if parent == nil {
+		return nil, errors.New("parent transaction is required")
+	}
+	if anchorIndex >= uint32(len(parent.TxOut)) {
+		return nil, errors.New("anchor index out of range")
+	}
+	if parent.TxOut[anchorIndex].Value > DustLimit {
+		return nil, errors.New("output at anchorIndex does not look like an anchor output")
+	}
+	if len(spendUTXOs) == 0 {
+		return nil, errors.New("at least one spend UTXO is required to pay the package fee")
+	}
+	if targetFeeRate < MinFeeRate {
+		return nil, errors.New("target fee rate must be positive")
+	}
+
+	tx := newDeterministicTx(TxVersion, 0)
+
+	parentHash := parent.TxHash()
+	anchorIn := wire.NewTxIn(wire.NewOutPoint(&parentHash, anchorIndex), nil, nil)
+	anchorIn.Sequence = wire.MaxTxInSequenceNum
+	tx.AddTxIn(anchorIn)
+
+	totalInput := parent.TxOut[anchorIndex].Value
+	for _, utxo := range spendUTXOs {
+		txIn := wire.NewTxIn(wire.NewOutPoint(&utxo.TxHash, utxo.OutputIndex), nil, nil)
+		txIn.Sequence = wire.MaxTxInSequenceNum
+		tx.AddTxIn(txIn)
+		totalInput += utxo.Amount
+	}
+
+	// The package fee is the parent's and child's combined weight at
+	// targetFeeRate, treating the parent's own fee as zero (see the
+	// doc comment above).
+	parentSize := estimateTxSize(parent, len(parent.TxIn), 0)
+	childSize := estimateTxSize(tx, len(tx.TxIn), 0)
+	fee := (parentSize + childSize) * targetFeeRate
+
+	change := totalInput - fee
+	if change <= 0 {
+		return nil, errors.New("spend UTXOs do not cover the package fee")
+	}
+
+	if change > DustLimit && changeAddr != "" {
+		changeAddress, err := btcutil.DecodeAddress(changeAddr, nil)
+		if err != nil {
+			return nil, err
+		}
+		changeScript, err := txscript.PayToAddrScript(changeAddress)
+		if err != nil {
+			return nil, err
+		}
+		tx.AddTxOut(wire.NewTxOut(change, changeScript))
+
+		childSize = estimateTxSize(tx, len(tx.TxIn), 0)
+		fee = (parentSize + childSize) * targetFeeRate
+		change = totalInput - fee
+
+		if change > DustLimit {
+			tx.TxOut[0].Value = change
+		} else {
+			tx.TxOut = tx.TxOut[:0]
+		}
+	}
+
+	return tx, nil
+}
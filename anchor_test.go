@@ -0,0 +1,144 @@
+package arkbuilders
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildAnchorSpend verifies BuildAnchorSpend assembles a valid CPFP
+// child spending a commitment tx's ephemeral anchor output.
+func TestBuildAnchorSpend(t *testing.T) {
+	builder := NewTxBuilder()
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+
+	commitTx, err := builder.BuildCommitmentTx(&CommitmentTxParams{
+		OperatorUTXOs:      []*UTXO{createTestUTXO(500000, 0)},
+		BatchAmount:        400000,
+		ConnectorAmount:    1000,
+		OperatorPubKey:     operatorPrivKey.PubKey(),
+		BatchExpiry:        800000,
+		FeeRate:            1,
+		UseEphemeralAnchor: true,
+	})
+	require.NoError(t, err)
+	anchorIndex := len(commitTx.TxOut) - 1
+
+	childTx, err := BuildAnchorSpend(commitTx, anchorIndex, 10, 5000)
+	require.NoError(t, err)
+	require.NotNil(t, childTx)
+
+	assert.Equal(t, int32(TxVersionTRUC), childTx.Version)
+	require.Len(t, childTx.TxIn, 1)
+	assert.Equal(t, commitTx.TxHash(), childTx.TxIn[0].PreviousOutPoint.Hash)
+	assert.Equal(t, uint32(anchorIndex), childTx.TxIn[0].PreviousOutPoint.Index)
+	assert.Equal(t, wire.MaxTxInSequenceNum, childTx.TxIn[0].Sequence)
+
+	// Test insufficient child fee
+	_, err = BuildAnchorSpend(commitTx, anchorIndex, 100, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not cover the child's own fee rate")
+
+	// Test wrong output index
+	_, err = BuildAnchorSpend(commitTx, 0, 10, 5000)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not an ephemeral anchor")
+
+	// Test nil parent
+	_, err = BuildAnchorSpend(nil, 0, 10, 5000)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "parent transaction is required")
+
+	t.Log("BuildAnchorSpend tests passed")
+}
+
+// TestCommitmentTxAnchorPolicy verifies AnchorPolicy's three modes and
+// that the anchor output stays last even with a memo attached.
+func TestCommitmentTxAnchorPolicy(t *testing.T) {
+	builder := NewTxBuilder()
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+	bumpPrivKey := createTestPrivKey(t, 0x03)
+
+	baseParams := &CommitmentTxParams{
+		OperatorUTXOs:   []*UTXO{createTestUTXO(500000, 0)},
+		BatchAmount:     400000,
+		ConnectorAmount: 1000,
+		OperatorPubKey:  operatorPrivKey.PubKey(),
+		BatchExpiry:     800000,
+		FeeRate:         1,
+		Memo:            []byte("anchor policy"),
+	}
+
+	noneTx, err := builder.BuildCommitmentTx(baseParams)
+	require.NoError(t, err)
+	assert.Equal(t, int32(TxVersion), noneTx.Version)
+	assert.Len(t, noneTx.TxOut, 3, "batch + connector + memo, no anchor")
+
+	ephemeralParams := *baseParams
+	ephemeralParams.AnchorPolicy = AnchorEphemeral
+	ephemeralTx, err := builder.BuildCommitmentTx(&ephemeralParams)
+	require.NoError(t, err)
+	assert.Equal(t, int32(TxVersionTRUC), ephemeralTx.Version)
+	require.Len(t, ephemeralTx.TxOut, 4, "batch + connector + memo + anchor")
+	lastOut := ephemeralTx.TxOut[len(ephemeralTx.TxOut)-1]
+	assert.Equal(t, int64(EphemeralAnchorAmount), lastOut.Value)
+	assert.Equal(t, ephemeralAnchorScript, lastOut.PkScript)
+
+	keyedParams := *baseParams
+	keyedParams.AnchorPolicy = AnchorKeyed
+	keyedParams.BumpKey = bumpPrivKey.PubKey()
+	keyedTx, err := builder.BuildCommitmentTx(&keyedParams)
+	require.NoError(t, err)
+	lastKeyedOut := keyedTx.TxOut[len(keyedTx.TxOut)-1]
+	assert.Equal(t, int64(DustLimit), lastKeyedOut.Value)
+	assert.NotEqual(t, ephemeralAnchorScript, lastKeyedOut.PkScript)
+
+	keyedParams.BumpKey = nil
+	_, err = builder.BuildCommitmentTx(&keyedParams)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "bump key is required")
+}
+
+// TestBuildBumpTx verifies BuildBumpTx assembles a package-relay CPFP
+// child spending a parent's anchor output plus extra spend UTXOs.
+func TestBuildBumpTx(t *testing.T) {
+	builder := NewTxBuilder()
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+
+	parent, err := builder.BuildCommitmentTx(&CommitmentTxParams{
+		OperatorUTXOs:      []*UTXO{createTestUTXO(500000, 0)},
+		BatchAmount:        400000,
+		ConnectorAmount:    1000,
+		OperatorPubKey:     operatorPrivKey.PubKey(),
+		BatchExpiry:        800000,
+		FeeRate:            1,
+		UseEphemeralAnchor: true,
+	})
+	require.NoError(t, err)
+	anchorIndex := uint32(len(parent.TxOut) - 1)
+
+	childTx, err := BuildBumpTx(parent, anchorIndex, []*UTXO{createTestUTXO(50000, 0)}, 10, "")
+	require.NoError(t, err)
+	require.Len(t, childTx.TxIn, 2, "anchor input plus the spend UTXO")
+	assert.Equal(t, parent.TxHash(), childTx.TxIn[0].PreviousOutPoint.Hash)
+	assert.Equal(t, anchorIndex, childTx.TxIn[0].PreviousOutPoint.Index)
+
+	// Test anchor index out of range
+	_, err = BuildBumpTx(parent, uint32(len(parent.TxOut)), []*UTXO{createTestUTXO(50000, 0)}, 10, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "out of range")
+
+	// Test no spend UTXOs
+	_, err = BuildBumpTx(parent, anchorIndex, nil, 10, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "at least one spend UTXO")
+
+	// Test nil parent
+	_, err = BuildBumpTx(nil, anchorIndex, []*UTXO{createTestUTXO(50000, 0)}, 10, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "parent transaction is required")
+
+	t.Log("BuildBumpTx tests passed")
+}
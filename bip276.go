@@ -0,0 +1,147 @@
+package arkbuilders
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// Network identifies the Bitcoin network a BIP-276 payload was tagged
+// for via its one-byte network flag.
+type Network byte
+
+const (
+	NetworkMainNet Network = iota
+	NetworkTestNet
+	NetworkRegTest
+	NetworkSigNet
+)
+
+// bip276ScriptPrefix is the BIP-276 human-readable prefix this package
+// uses for raw script/key payloads.
+const bip276ScriptPrefix = "bitcoin-script:"
+
+// bip276Version is the only payload version EncodeScriptBIP276 produces
+// and DecodeScriptBIP276 accepts.
+const bip276Version byte = 1
+
+// bip276ChecksumLen is the length, in bytes, of the truncated
+// double-SHA256 checksum appended to every BIP-276 payload.
+const bip276ChecksumLen = 4
+
+// EncodeScriptBIP276 encodes script as a BIP-276 string: the
+// "bitcoin-script:" prefix followed by the hex encoding of a one-byte
+// version, a one-byte network flag, the script bytes themselves, and a
+// trailing 4-byte truncated double-SHA256 checksum over everything that
+// precedes it. The result is a single copy-pasteable token, handy for
+// sharing a generated tapscript leaf or internal key in an issue report
+// or a cross-implementation test fixture.
+func EncodeScriptBIP276(script []byte, network chaincfg.Params) (string, error) {
+	flag, err := bip276NetworkFlag(network)
+	if err != nil {
+		return "", err
+	}
+
+	payload := make([]byte, 0, 2+len(script)+bip276ChecksumLen)
+	payload = append(payload, bip276Version, byte(flag))
+	payload = append(payload, script...)
+	checksum := chainhash.DoubleHashB(payload)[:bip276ChecksumLen]
+	payload = append(payload, checksum...)
+
+	return bip276ScriptPrefix + hex.EncodeToString(payload), nil
+}
+
+// DecodeScriptBIP276 parses a string produced by EncodeScriptBIP276 back
+// into its script bytes and the Network it was tagged with, verifying
+// the trailing checksum along the way.
+func DecodeScriptBIP276(s string) ([]byte, Network, error) {
+	hexPart := strings.TrimPrefix(s, bip276ScriptPrefix)
+	if hexPart == s {
+		return nil, 0, errors.New("not a bitcoin-script BIP-276 string")
+	}
+
+	raw, err := hex.DecodeString(hexPart)
+	if err != nil {
+		return nil, 0, fmt.Errorf("decoding BIP-276 payload: %w", err)
+	}
+	if len(raw) < 2+bip276ChecksumLen {
+		return nil, 0, errors.New("BIP-276 payload too short")
+	}
+
+	payload, checksum := raw[:len(raw)-bip276ChecksumLen], raw[len(raw)-bip276ChecksumLen:]
+	if want := chainhash.DoubleHashB(payload)[:bip276ChecksumLen]; !bytes.Equal(checksum, want) {
+		return nil, 0, errors.New("BIP-276 checksum mismatch")
+	}
+
+	if version := payload[0]; version != bip276Version {
+		return nil, 0, fmt.Errorf("unsupported BIP-276 version %d", version)
+	}
+
+	return payload[2:], Network(payload[1]), nil
+}
+
+// bip276NetworkFlag maps a chaincfg.Params to the one-byte network flag
+// BIP-276 carries alongside its version.
+func bip276NetworkFlag(network chaincfg.Params) (Network, error) {
+	switch network.Net {
+	case chaincfg.MainNetParams.Net:
+		return NetworkMainNet, nil
+	case chaincfg.TestNet3Params.Net:
+		return NetworkTestNet, nil
+	case chaincfg.RegressionNetParams.Net:
+		return NetworkRegTest, nil
+	case chaincfg.SigNetParams.Net:
+		return NetworkSigNet, nil
+	default:
+		return 0, fmt.Errorf("unrecognized network %q", network.Name)
+	}
+}
+
+// DumpScripts walks packet's inputs and outputs and returns one labeled
+// BIP-276 string per Taproot internal key and tapscript leaf it finds,
+// in input/output order, so a support ticket or integration test can
+// hand over every script a PSBT depends on as a single copy-pasteable
+// list. Outputs are checked for an internal key only, never for leaf
+// scripts; none of this package's BuildXxxPSBT builders set either on an
+// output, so for their packets only inputs contribute entries.
+func (tb *TxBuilder) DumpScripts(packet *psbt.Packet, network chaincfg.Params) ([]string, error) {
+	if packet == nil {
+		return nil, errors.New("psbt packet is required")
+	}
+
+	var dumped []string
+	for i, input := range packet.Inputs {
+		if len(input.TaprootInternalKey) > 0 {
+			encoded, err := EncodeScriptBIP276(input.TaprootInternalKey, network)
+			if err != nil {
+				return nil, err
+			}
+			dumped = append(dumped, fmt.Sprintf("input %d internal-key: %s", i, encoded))
+		}
+		for leafIdx, leaf := range input.TaprootLeafScript {
+			encoded, err := EncodeScriptBIP276(leaf.Script, network)
+			if err != nil {
+				return nil, err
+			}
+			dumped = append(dumped, fmt.Sprintf("input %d leaf %d: %s", i, leafIdx, encoded))
+		}
+	}
+
+	for i, output := range packet.Outputs {
+		if len(output.TaprootInternalKey) > 0 {
+			encoded, err := EncodeScriptBIP276(output.TaprootInternalKey, network)
+			if err != nil {
+				return nil, err
+			}
+			dumped = append(dumped, fmt.Sprintf("output %d internal-key: %s", i, encoded))
+		}
+	}
+
+	return dumped, nil
+}
@@ -0,0 +1,108 @@
+package arkbuilders
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncodeDecodeScriptBIP276RoundTrip checks that decoding an encoded
+// script recovers the original bytes and network across every network
+// this package recognizes.
+func TestEncodeDecodeScriptBIP276RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  []byte
+		network chaincfg.Params
+		want    Network
+	}{
+		{"mainnet", []byte{0x51}, chaincfg.MainNetParams, NetworkMainNet},
+		{"testnet3", []byte{0x00, 0x14, 0x01, 0x02}, chaincfg.TestNet3Params, NetworkTestNet},
+		{"regtest", []byte{}, chaincfg.RegressionNetParams, NetworkRegTest},
+		{"signet", []byte{0xde, 0xad, 0xbe, 0xef}, chaincfg.SigNetParams, NetworkSigNet},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded, err := EncodeScriptBIP276(tc.script, tc.network)
+			require.NoError(t, err)
+			assert.Contains(t, encoded, bip276ScriptPrefix)
+
+			script, network, err := DecodeScriptBIP276(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, tc.script, script)
+			assert.Equal(t, tc.want, network)
+		})
+	}
+}
+
+// TestDecodeScriptBIP276Errors checks that malformed or tampered BIP-276
+// strings are rejected rather than silently truncated or misread.
+func TestDecodeScriptBIP276Errors(t *testing.T) {
+	encoded, err := EncodeScriptBIP276([]byte{0x51}, chaincfg.MainNetParams)
+	require.NoError(t, err)
+
+	t.Run("missing prefix", func(t *testing.T) {
+		_, _, err := DecodeScriptBIP276("0102")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid hex", func(t *testing.T) {
+		_, _, err := DecodeScriptBIP276(bip276ScriptPrefix + "zz")
+		assert.Error(t, err)
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		_, _, err := DecodeScriptBIP276(bip276ScriptPrefix + "0102")
+		assert.Error(t, err)
+	})
+
+	t.Run("corrupted checksum", func(t *testing.T) {
+		corrupted := encoded[:len(encoded)-1] + "0"
+		if corrupted == encoded {
+			corrupted = encoded[:len(encoded)-1] + "1"
+		}
+		_, _, err := DecodeScriptBIP276(corrupted)
+		assert.Error(t, err)
+	})
+
+	t.Run("unrecognized network", func(t *testing.T) {
+		_, err := EncodeScriptBIP276([]byte{0x51}, chaincfg.Params{Name: "not-a-real-network"})
+		assert.Error(t, err)
+	})
+}
+
+// TestDumpScripts verifies DumpScripts labels a boarding PSBT's internal
+// key and that each label's payload round-trips back through
+// DecodeScriptBIP276 to the original bytes.
+func TestDumpScripts(t *testing.T) {
+	builder := NewTxBuilder()
+	userPrivKey := createTestPrivKey(t, 0x01)
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+
+	packet, err := builder.BuildBoardingPSBT(&BoardingTxParams{
+		FundingUTXO:    createTestUTXO(100000, 0),
+		Amount:         90000,
+		UserPubKey:     userPrivKey.PubKey(),
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		TimeoutBlocks:  144,
+		FeeRate:        1,
+	})
+	require.NoError(t, err)
+
+	dumped, err := builder.DumpScripts(packet, chaincfg.MainNetParams)
+	require.NoError(t, err)
+	require.Len(t, dumped, 1)
+	assert.Contains(t, dumped[0], "input 0 internal-key:")
+
+	prefixed := dumped[0][len("input 0 internal-key: "):]
+	key, network, err := DecodeScriptBIP276(prefixed)
+	require.NoError(t, err)
+	assert.Equal(t, packet.Inputs[0].TaprootInternalKey, key)
+	assert.Equal(t, NetworkMainNet, network)
+
+	_, err = builder.DumpScripts(nil, chaincfg.MainNetParams)
+	assert.Error(t, err)
+}
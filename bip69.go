@@ -0,0 +1,78 @@
+package arkbuilders
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// BIP69SortTx sorts tx's inputs and outputs into BIP-69 canonical order in
+// place: inputs ascending by (previous outpoint hash, previous outpoint
+// index), outputs ascending by (amount, script). Two transactions spending
+// the same set of inputs and paying the same set of outputs are therefore
+// byte-identical regardless of the order callers supplied them in, which is
+// what lets independently-built Ark commitments hash to the same txid.
+//
+// BuildBoardingTx, BuildCommitmentTx, and BuildUnilateralExitTx call this
+// (via sortTxInputs/sortTxOutputs) whenever TxBuilder.CanonicalOrdering is
+// set, which NewTxBuilder defaults to true. BuildCommitmentTx only sorts
+// its inputs: its batch/connector outputs are identified by position
+// elsewhere and must stay in that order. BuildForfeitTx sorts neither:
+// its two inputs are VTXO and connector-anchor by convention, and its
+// outputs are operator/anchor/memo by convention, and BuildForfeitPSBT
+// addresses the inputs as packet.Inputs[0] and [1] on that assumption, so
+// reordering either would silently mismatch the PSBT's witness data
+// against the signed transaction.
+func BIP69SortTx(tx *wire.MsgTx) {
+	sortTxInputs(tx)
+	sortTxOutputs(tx)
+}
+
+// sortTxInputs sorts transaction inputs deterministically per BIP-69:
+// ascending by previous outpoint hash, then by previous outpoint index.
+// BIP-69 compares txids in their displayed (big-endian hex) byte order,
+// the reverse of the internal order chainhash.Hash stores, so each hash
+// is byte-reversed before comparing.
+func sortTxInputs(tx *wire.MsgTx) {
+	sort.Slice(tx.TxIn, func(i, j int) bool {
+		hi := tx.TxIn[i].PreviousOutPoint.Hash
+		hj := tx.TxIn[j].PreviousOutPoint.Hash
+		if hi != hj {
+			for b := 0; b < chainhash.HashSize/2; b++ {
+				hi[b], hi[chainhash.HashSize-1-b] = hi[chainhash.HashSize-1-b], hi[b]
+				hj[b], hj[chainhash.HashSize-1-b] = hj[chainhash.HashSize-1-b], hj[b]
+			}
+			return bytes.Compare(hi[:], hj[:]) < 0
+		}
+		return tx.TxIn[i].PreviousOutPoint.Index < tx.TxIn[j].PreviousOutPoint.Index
+	})
+}
+
+// sortTxOutputs sorts transaction outputs deterministically per BIP-69:
+// ascending by amount, then by script compared byte-for-byte (a shorter
+// script that is a prefix of a longer one sorts first).
+func sortTxOutputs(tx *wire.MsgTx) {
+	sort.Slice(tx.TxOut, func(i, j int) bool {
+		if tx.TxOut[i].Value != tx.TxOut[j].Value {
+			return tx.TxOut[i].Value < tx.TxOut[j].Value
+		}
+		return bytes.Compare(tx.TxOut[i].PkScript, tx.TxOut[j].PkScript) < 0
+	})
+}
+
+// sortScripts sorts scripts into the same byte-for-byte lexicographic
+// order sortTxOutputs uses for PkScripts, so taproot leaf construction
+// (see CreateTaprootScript callers) orders its script-path leaves
+// deterministically regardless of the order they were derived in.
+func sortScripts(scripts [][]byte) [][]byte {
+	sorted := make([][]byte, len(scripts))
+	copy(sorted, scripts)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+
+	return sorted
+}
@@ -0,0 +1,183 @@
+package arkbuilders
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBIP69SortTxInputs is a table-driven check against the BIP-69
+// reference ordering for inputs: ascending by (previous outpoint hash,
+// previous outpoint index). Hashes below are chosen so that their
+// internal byte order (what chainhash.Hash stores) sorts them the other
+// way round from their displayed hex order, which is the order BIP-69
+// compares: the same trap the reference vectors are meant to catch.
+func TestBIP69SortTxInputs(t *testing.T) {
+	hashLow, _ := chainhash.NewHashFromStr("0000000000000000000000000000000000000000000000000000000000000001")
+	hashHigh, _ := chainhash.NewHashFromStr("0100000000000000000000000000000000000000000000000000000000000000")
+
+	tests := []struct {
+		name    string
+		tx      *wire.MsgTx
+		wantSeq []wire.OutPoint // expected PreviousOutPoint order after sort
+	}{
+		{
+			name: "sorts by hash when hashes differ",
+			tx: &wire.MsgTx{TxIn: []*wire.TxIn{
+				wire.NewTxIn(wire.NewOutPoint(hashHigh, 0), nil, nil),
+				wire.NewTxIn(wire.NewOutPoint(hashLow, 0), nil, nil),
+			}},
+			wantSeq: []wire.OutPoint{
+				*wire.NewOutPoint(hashLow, 0),
+				*wire.NewOutPoint(hashHigh, 0),
+			},
+		},
+		{
+			name: "sorts by index when hashes are equal",
+			tx: &wire.MsgTx{TxIn: []*wire.TxIn{
+				wire.NewTxIn(wire.NewOutPoint(hashLow, 7), nil, nil),
+				wire.NewTxIn(wire.NewOutPoint(hashLow, 1), nil, nil),
+				wire.NewTxIn(wire.NewOutPoint(hashLow, 3), nil, nil),
+			}},
+			wantSeq: []wire.OutPoint{
+				*wire.NewOutPoint(hashLow, 1),
+				*wire.NewOutPoint(hashLow, 3),
+				*wire.NewOutPoint(hashLow, 7),
+			},
+		},
+		{
+			name:    "empty transaction is a no-op",
+			tx:      &wire.MsgTx{TxIn: []*wire.TxIn{}},
+			wantSeq: []wire.OutPoint{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sortTxInputs(tc.tx)
+			require_ := assert.New(t)
+			require_.Len(tc.tx.TxIn, len(tc.wantSeq))
+			for i, want := range tc.wantSeq {
+				require_.Equal(want, tc.tx.TxIn[i].PreviousOutPoint)
+			}
+		})
+	}
+}
+
+// TestBIP69SortTxOutputs is a table-driven check against the BIP-69
+// reference ordering for outputs: ascending by (amount, script), where
+// the script comparison is byte-for-byte rather than length-first, so a
+// shorter script that is a strict prefix of a longer one sorts first.
+func TestBIP69SortTxOutputs(t *testing.T) {
+	tests := []struct {
+		name       string
+		tx         *wire.MsgTx
+		wantScript [][]byte
+		wantValue  []int64
+	}{
+		{
+			name: "sorts by amount ascending",
+			tx: &wire.MsgTx{TxOut: []*wire.TxOut{
+				wire.NewTxOut(300, []byte{0xaa}),
+				wire.NewTxOut(100, []byte{0xbb}),
+				wire.NewTxOut(200, []byte{0xcc}),
+			}},
+			wantValue:  []int64{100, 200, 300},
+			wantScript: [][]byte{{0xbb}, {0xcc}, {0xaa}},
+		},
+		{
+			name: "ties on amount fall back to byte-for-byte script order, not length-first",
+			tx: &wire.MsgTx{TxOut: []*wire.TxOut{
+				wire.NewTxOut(1000, []byte{0x01, 0x00}),
+				wire.NewTxOut(1000, []byte{0x00, 0xff}),
+				wire.NewTxOut(1000, []byte{0x00}),
+			}},
+			wantValue:  []int64{1000, 1000, 1000},
+			wantScript: [][]byte{{0x00}, {0x00, 0xff}, {0x01, 0x00}},
+		},
+		{
+			name:       "empty transaction is a no-op",
+			tx:         &wire.MsgTx{TxOut: []*wire.TxOut{}},
+			wantValue:  []int64{},
+			wantScript: [][]byte{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sortTxOutputs(tc.tx)
+			a := assert.New(t)
+			a.Len(tc.tx.TxOut, len(tc.wantValue))
+			for i := range tc.wantValue {
+				a.Equal(tc.wantValue[i], tc.tx.TxOut[i].Value)
+				a.Equal(tc.wantScript[i], tc.tx.TxOut[i].PkScript)
+			}
+		})
+	}
+}
+
+// TestBIP69SortTx verifies BIP69SortTx applies both the input and output
+// orderings in a single call.
+func TestBIP69SortTx(t *testing.T) {
+	hashLow, _ := chainhash.NewHashFromStr("0000000000000000000000000000000000000000000000000000000000000001")
+	hashHigh, _ := chainhash.NewHashFromStr("0000000000000000000000000000000000000000000000000000000000000002")
+
+	tx := &wire.MsgTx{
+		TxIn: []*wire.TxIn{
+			wire.NewTxIn(wire.NewOutPoint(hashHigh, 0), nil, nil),
+			wire.NewTxIn(wire.NewOutPoint(hashLow, 0), nil, nil),
+		},
+		TxOut: []*wire.TxOut{
+			wire.NewTxOut(500, []byte{0x02}),
+			wire.NewTxOut(100, []byte{0x01}),
+		},
+	}
+
+	BIP69SortTx(tx)
+
+	assert.Equal(t, *wire.NewOutPoint(hashLow, 0), tx.TxIn[0].PreviousOutPoint)
+	assert.Equal(t, *wire.NewOutPoint(hashHigh, 0), tx.TxIn[1].PreviousOutPoint)
+	assert.Equal(t, int64(100), tx.TxOut[0].Value)
+	assert.Equal(t, int64(500), tx.TxOut[1].Value)
+}
+
+// TestCanonicalOrderingToggle verifies that BuildBoardingTx with
+// TxBuilder.CanonicalOrdering set emits its outputs in the order
+// sortTxOutputs gives the same outputs built without it, and that
+// NewTxBuilder enables CanonicalOrdering by default.
+func TestCanonicalOrderingToggle(t *testing.T) {
+	userPrivKey := createTestPrivKey(t, 0x01)
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+
+	params := &BoardingTxParams{
+		FundingUTXO:    createTestUTXO(100000, 0),
+		UserPubKey:     userPrivKey.PubKey(),
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		Amount:         50000,
+		TimeoutBlocks:  144,
+		FeeRate:        1,
+		ChangeAddress:  "",
+	}
+
+	canonical := &TxBuilder{CanonicalOrdering: true}
+	canonicalTx, err := canonical.BuildBoardingTx(params)
+	assert := assert.New(t)
+	assert.NoError(err)
+
+	verbatim := &TxBuilder{CanonicalOrdering: false}
+	verbatimTx, err := verbatim.BuildBoardingTx(params)
+	assert.NoError(err)
+
+	// A single output is trivially "sorted" either way, but both builders
+	// must agree with sortTxOutputs' own verdict on this transaction.
+	wantSorted := wire.NewMsgTx(canonicalTx.Version)
+	for _, out := range verbatimTx.TxOut {
+		wantSorted.AddTxOut(out)
+	}
+	sortTxOutputs(wantSorted)
+	assert.Equal(wantSorted.TxOut, canonicalTx.TxOut)
+
+	assert.True(NewTxBuilder().CanonicalOrdering, "NewTxBuilder should default to canonical ordering")
+}
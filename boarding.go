@@ -2,11 +2,12 @@ package arkbuilders
 
 import (
 	"errors"
-	"sort"
 
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
+
+	"github.com/utexo/ark-tx-builders/coinselect"
 )
 
 // BuildBoardingTx creates a boarding transaction for depositing into Ark
@@ -15,15 +16,29 @@ import (
 // - Timeout path: user signature + relative timelock
 func (tb *TxBuilder) BuildBoardingTx(params *BoardingTxParams) (*wire.MsgTx, error) {
 	// Validate parameters
-	if params.FundingUTXO == nil {
+	if params.FundingUTXO != nil && len(params.FundingUTXOs) > 0 {
+		return nil, errors.New("funding UTXO and funding UTXOs are mutually exclusive")
+	}
+	if params.FundingUTXO == nil && len(params.FundingUTXOs) == 0 {
 		return nil, errors.New("funding UTXO is required")
 	}
+	if params.FundingUTXO != nil && params.FundingUTXO.Amount <= 0 {
+		return nil, errors.New("funding UTXO amount must be positive")
+	}
+	for _, u := range params.FundingUTXOs {
+		if u.Amount <= 0 {
+			return nil, errors.New("funding UTXO amount must be positive")
+		}
+	}
 	if params.UserPubKey == nil || params.OperatorPubKey == nil {
 		return nil, errors.New("user and operator public keys are required")
 	}
 	if params.Amount <= 0 {
 		return nil, errors.New("amount must be positive")
 	}
+	if params.Amount < DustLimit {
+		return nil, errors.New("amount below dust limit")
+	}
 	if params.FeeRate < MinFeeRate {
 		params.FeeRate = MinFeeRate
 	}
@@ -31,14 +46,52 @@ func (tb *TxBuilder) BuildBoardingTx(params *BoardingTxParams) (*wire.MsgTx, err
 	// Create new transaction with deterministic fields
 	tx := newDeterministicTx(TxVersion, 0)
 
-	// Add input: spending fundingUTXO with sequence 0xFFFFFFFD
-	txIn := wire.NewTxIn(
-		wire.NewOutPoint(&params.FundingUTXO.TxHash, params.FundingUTXO.OutputIndex),
-		nil,
-		nil,
+	// Parse the change address once up front: both the single-UTXO path
+	// below and coin selection need its script (coin selection to size
+	// the change output it might add, the single-UTXO path to build it).
+	var changeScript []byte
+	if params.ChangeAddress != "" {
+		changeAddr, err := btcutil.DecodeAddress(params.ChangeAddress, nil)
+		if err != nil {
+			return nil, err
+		}
+		changeScript, err = txscript.PayToAddrScript(changeAddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// fundingTotal is the sum of whatever funds this boarding tx, and
+	// selection is the coin-selection outcome when funding from
+	// FundingUTXOs (nil when a single FundingUTXO was given instead).
+	var (
+		fundingTotal int64
+		selection    *coinselect.SelectionResult
 	)
-	txIn.Sequence = SequenceBoardingTx
-	tx.AddTxIn(txIn)
+
+	if params.FundingUTXO != nil {
+		// Add input: spending fundingUTXO with sequence 0xFFFFFFFD
+		txIn := wire.NewTxIn(
+			wire.NewOutPoint(&params.FundingUTXO.TxHash, params.FundingUTXO.OutputIndex),
+			nil,
+			nil,
+		)
+		txIn.Sequence = SequenceBoardingTx
+		tx.AddTxIn(txIn)
+		fundingTotal = params.FundingUTXO.Amount
+	} else {
+		result, err := coinselect.Select(toCoinselectUTXOs(params.FundingUTXOs), params.Amount, params.FeeRate, len(changeScript), params.FundingStrategy)
+		if err != nil {
+			return nil, err
+		}
+		selection = &result
+		for _, u := range result.Selected {
+			txIn := wire.NewTxIn(wire.NewOutPoint(&u.TxHash, u.OutputIndex), nil, nil)
+			txIn.Sequence = SequenceBoardingTx
+			tx.AddTxIn(txIn)
+			fundingTotal += u.Amount
+		}
+	}
 
 	// Build Taproot output script
 	// Path 1: MuSig2 aggregated key (cooperative)
@@ -68,46 +121,73 @@ func (tb *TxBuilder) BuildBoardingTx(params *BoardingTxParams) (*wire.MsgTx, err
 	// Add main output
 	tx.AddTxOut(wire.NewTxOut(params.Amount, taprootScript))
 
-	// Calculate fee
-	estimatedSize := estimateTxSize(tx, 1, 0) // 1 input, no witness data for estimation
-	fee := estimatedSize * params.FeeRate
-
-	// Check if we need a change output
-	change := params.FundingUTXO.Amount - params.Amount - fee
-	if change > DustLimit && params.ChangeAddress != "" {
-		// Parse change address
-		changeAddr, err := btcutil.DecodeAddress(params.ChangeAddress, nil)
-		if err != nil {
-			return nil, err
+	if selection != nil {
+		// Coin selection already converged on the exact fee and change
+		// for this input set (re-estimating vsize pass by pass as it
+		// went), so just add the change output it settled on instead of
+		// re-deriving it from estimateTxSize.
+		if selection.HasChange {
+			tx.AddTxOut(wire.NewTxOut(selection.Change, changeScript))
 		}
-		changeScript, err := txscript.PayToAddrScript(changeAddr)
-		if err != nil {
-			return nil, err
+	} else {
+		// Calculate fee
+		estimatedSize := estimateTxSize(tx, 1, 0) // 1 input, no witness data for estimation
+		fee := estimatedSize * params.FeeRate
+
+		// Check if we need a change output
+		change := fundingTotal - params.Amount - fee
+		if change > DustLimit && params.ChangeAddress != "" {
+			// Add change output
+			tx.AddTxOut(wire.NewTxOut(change, changeScript))
+
+			// Re-estimate fee with change output
+			estimatedSize = estimateTxSize(tx, 1, 0)
+			fee = estimatedSize * params.FeeRate
+			change = fundingTotal - params.Amount - fee
+
+			// Update change amount
+			if change > DustLimit {
+				tx.TxOut[1].Value = change
+			} else {
+				// Remove change output if it would be dust
+				tx.TxOut = tx.TxOut[:1]
+			}
 		}
+	}
 
-		// Add change output
-		tx.AddTxOut(wire.NewTxOut(change, changeScript))
-
-		// Re-estimate fee with change output
-		estimatedSize = estimateTxSize(tx, 1, 0)
-		fee = estimatedSize * params.FeeRate
-		change = params.FundingUTXO.Amount - params.Amount - fee
+	// Sort inputs and outputs deterministically (BIP-69)
+	if tb.CanonicalOrdering {
+		sortTxInputs(tx)
+		sortTxOutputs(tx)
+	}
 
-		// Update change amount
-		if change > DustLimit {
-			tx.TxOut[1].Value = change
-		} else {
-			// Remove change output if it would be dust
-			tx.TxOut = tx.TxOut[:1]
+	// Attach the memo last so it never disturbs the sorted outputs above.
+	if len(params.Memo) > 0 {
+		memoOutput, err := buildMemoOutput(params.Memo)
+		if err != nil {
+			return nil, err
 		}
+		tx.AddTxOut(memoOutput)
 	}
 
-	// Sort outputs deterministically (BIP-69 style)
-	sortTxOutputs(tx)
-
 	return tx, nil
 }
 
+// toCoinselectUTXOs converts this module's UTXO type to coinselect's own,
+// which coinselect defines independently to avoid an import cycle back
+// into this package (see coinselect/coinselect.go).
+func toCoinselectUTXOs(utxos []*UTXO) []*coinselect.UTXO {
+	out := make([]*coinselect.UTXO, len(utxos))
+	for i, u := range utxos {
+		out[i] = &coinselect.UTXO{
+			TxHash:      u.TxHash,
+			OutputIndex: u.OutputIndex,
+			Amount:      u.Amount,
+		}
+	}
+	return out
+}
+
 // estimateTxSize estimates the size of a transaction in vbytes
 func estimateTxSize(tx *wire.MsgTx, numInputs, witnessSize int) int64 {
 	// Base size (non-witness data)
@@ -126,54 +206,3 @@ func estimateTxSize(tx *wire.MsgTx, numInputs, witnessSize int) int64 {
 
 	return int64(vsize)
 }
-
-// sortScripts sorts scripts deterministically by their byte representation
-func sortScripts(scripts [][]byte) [][]byte {
-	sorted := make([][]byte, len(scripts))
-	copy(sorted, scripts)
-
-	sort.Slice(sorted, func(i, j int) bool {
-		// First compare by length
-		if len(sorted[i]) != len(sorted[j]) {
-			return len(sorted[i]) < len(sorted[j])
-		}
-		// Then compare lexicographically
-		for k := 0; k < len(sorted[i]); k++ {
-			if sorted[i][k] != sorted[j][k] {
-				return sorted[i][k] < sorted[j][k]
-			}
-		}
-		return false
-	})
-
-	return sorted
-}
-
-// sortTxOutputs sorts transaction outputs deterministically
-// Based on BIP-69: amount ascending, then script ascending
-func sortTxOutputs(tx *wire.MsgTx) {
-	sort.Slice(tx.TxOut, func(i, j int) bool {
-		// First compare by amount
-		if tx.TxOut[i].Value != tx.TxOut[j].Value {
-			return tx.TxOut[i].Value < tx.TxOut[j].Value
-		}
-
-		// Then compare by script
-		iScript := tx.TxOut[i].PkScript
-		jScript := tx.TxOut[j].PkScript
-
-		// Compare length
-		if len(iScript) != len(jScript) {
-			return len(iScript) < len(jScript)
-		}
-
-		// Compare lexicographically
-		for k := 0; k < len(iScript); k++ {
-			if iScript[k] != jScript[k] {
-				return iScript[k] < jScript[k]
-			}
-		}
-
-		return false
-	})
-}
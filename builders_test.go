@@ -424,6 +424,28 @@ func TestTransactionBasicProperties(t *testing.T) {
 	assert.Equal(t, uint32(SequenceForfeitTx), forfeitTx.TxIn[0].Sequence,
 		"Sequence should be 0xFFFFFFFF")
 
+	// Test Commitment Transaction with an ephemeral anchor (TRUC/v3)
+	commitParams.UseEphemeralAnchor = true
+	anchorCommitTx, err := builder.BuildCommitmentTx(commitParams)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(TxVersionTRUC), anchorCommitTx.Version, "Version should be 3")
+	assert.Len(t, anchorCommitTx.TxOut, 3, "Should have 3 outputs (batch + connector + anchor)")
+	assert.Equal(t, int64(EphemeralAnchorAmount), anchorCommitTx.TxOut[2].Value,
+		"Anchor output should carry zero value")
+	assert.Equal(t, ephemeralAnchorScript, anchorCommitTx.TxOut[2].PkScript)
+
+	// Test Forfeit Transaction with an ephemeral anchor (TRUC/v3)
+	forfeitParams.UseEphemeralAnchor = true
+	anchorForfeitTx, err := builder.BuildForfeitTx(forfeitParams)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(TxVersionTRUC), anchorForfeitTx.Version, "Version should be 3")
+	assert.Len(t, anchorForfeitTx.TxOut, 2, "Should have 2 outputs (operator + anchor)")
+	assert.Equal(t, int64(EphemeralAnchorAmount), anchorForfeitTx.TxOut[1].Value,
+		"Anchor output should carry zero value")
+	assert.Equal(t, ephemeralAnchorScript, anchorForfeitTx.TxOut[1].PkScript)
+
 	t.Log("All basic transaction properties verified")
 }
 
@@ -962,8 +984,13 @@ func TestCommitmentWithBoardingOutputs(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, tx)
 
-	// Verify inputs are sorted
-	assert.Len(t, tx.TxIn, 4, "Should have 4 inputs (2 operator + 2 boarding)")
+	// BuildCommitmentTx selects only as many operator UTXOs as are
+	// needed to cover the batch and connector outputs (less what the
+	// boarding outputs already contribute), rather than spending every
+	// operator UTXO passed in: the single 300000 operator UTXO alone
+	// covers the ~250546 sat still owed after the 150000 sats of
+	// boarding outputs, so the 200000 operator UTXO is left unselected.
+	assert.Len(t, tx.TxIn, 3, "Should have 3 inputs (1 selected operator + 2 boarding)")
 
 	// Verify outputs
 	assert.Len(t, tx.TxOut, 2, "Should have 2 outputs (batch + connector)")
@@ -1018,3 +1045,63 @@ func TestFeeRateValidation(t *testing.T) {
 
 	t.Log("Fee rate validation tests passed")
 }
+
+// TestBoardingFundingUTXOsCoinSelection verifies that BuildBoardingTx can
+// fund itself from a candidate set via coin selection instead of a single
+// preselected UTXO.
+func TestBoardingFundingUTXOsCoinSelection(t *testing.T) {
+	builder := NewTxBuilder()
+
+	userPrivKey := createTestPrivKey(t, 0x01)
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+
+	params := &BoardingTxParams{
+		FundingUTXOs: []*UTXO{
+			createTestUTXO(40000, 0),
+			createTestUTXO(40000, 1),
+			createTestUTXO(40000, 2),
+		},
+		Amount:         90000,
+		UserPubKey:     userPrivKey.PubKey(),
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		TimeoutBlocks:  144,
+		ChangeAddress:  "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+		FeeRate:        1,
+	}
+
+	tx, err := builder.BuildBoardingTx(params)
+	require.NoError(t, err)
+	require.Len(t, tx.TxIn, 3, "all three candidates are needed to cover Amount plus fees")
+	assert.Len(t, tx.TxOut, 2, "leftover above dust should surface as a change output")
+
+	// FundingUTXO and FundingUTXOs are mutually exclusive.
+	params.FundingUTXO = createTestUTXO(100000, 3)
+	_, err = builder.BuildBoardingTx(params)
+	assert.Error(t, err)
+}
+
+// TestCommitmentOperatorUTXOsCoinSelection verifies that BuildCommitmentTx
+// spends only as many operator UTXOs as are needed when OperatorUTXOs
+// carries more than that, instead of burning the rest as fee.
+func TestCommitmentOperatorUTXOsCoinSelection(t *testing.T) {
+	builder := NewTxBuilder()
+
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+
+	params := &CommitmentTxParams{
+		OperatorUTXOs: []*UTXO{
+			createTestUTXO(500000, 0),
+			createTestUTXO(500000, 1),
+			createTestUTXO(500000, 2),
+		},
+		BatchAmount:     400000,
+		ConnectorAmount: 1000,
+		OperatorPubKey:  operatorPrivKey.PubKey(),
+		BatchExpiry:     800000,
+		FeeRate:         1,
+	}
+
+	tx, err := builder.BuildCommitmentTx(params)
+	require.NoError(t, err)
+	assert.Len(t, tx.TxIn, 1, "a single operator UTXO already covers the batch and connector")
+}
@@ -0,0 +1,342 @@
+// Package coinselect picks which UTXOs fund a transaction and converges
+// on the exact fee a given selection will pay, so callers in the parent
+// module don't have to preselect inputs or guess at fees themselves.
+//
+// It intentionally defines its own UTXO type rather than importing the
+// parent module's: BuildBoardingTx and BuildCommitmentTx call into this
+// package, so the parent module importing this one back would be a
+// cycle. Callers convert their own UTXOs to coinselect.UTXO at the call
+// site (see boarding.go and commitment.go).
+package coinselect
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Strategy selects which coin-selection algorithm Select uses to choose
+// among the available UTXOs.
+type Strategy int
+
+const (
+	// BranchAndBound searches for a subset whose total matches target
+	// plus fees as closely as possible, preferring an exact match (no
+	// change output, and so no change output's own fee cost) over one
+	// that requires change.
+	BranchAndBound Strategy = iota
+	// LargestFirst adds UTXOs in descending amount order until the
+	// target is covered, minimizing the number of inputs used.
+	LargestFirst
+	// SingleRandomDraw adds UTXOs in an order derived from each coin's
+	// own outpoint (rather than the wall-clock or PRNG randomness the
+	// name usually implies), so that, consistent with the rest of this
+	// module, the same coin set always selects the same way.
+	SingleRandomDraw
+)
+
+// dustLimit mirrors the parent module's DustLimit (546 satoshis, the
+// P2TR dust threshold): a change output below it is folded into the fee
+// instead of being created.
+const dustLimit = 546
+
+// maxSelectionPasses bounds how many times Select re-estimates the
+// transaction's vsize and reselects before giving up, so a pathological
+// input set can't loop indefinitely.
+const maxSelectionPasses = 8
+
+// UTXO is the coin-selection view of a spendable output: enough to size
+// and total a candidate input set, plus the witness it will need once
+// spent, so Select can account for its exact weight rather than a flat
+// estimate.
+type UTXO struct {
+	TxHash      chainhash.Hash
+	OutputIndex uint32
+	Amount      int64
+
+	// WitnessSize is the byte size of the witness this coin will be
+	// spent with: 64 for a plain P2TR key-path spend (the default used
+	// when this is left zero), or the caller-computed size of a
+	// specific Taproot leaf's script-path witness (script + control
+	// block + signature) when spending that leaf instead.
+	WitnessSize int
+}
+
+func (u *UTXO) outpoint() wire.OutPoint {
+	return wire.OutPoint{Hash: u.TxHash, Index: u.OutputIndex}
+}
+
+func (u *UTXO) witnessSize() int {
+	if u.WitnessSize > 0 {
+		return u.WitnessSize
+	}
+	return 64
+}
+
+// SelectionResult is the outcome of a Select call.
+type SelectionResult struct {
+	Selected []*UTXO
+	Fee      int64
+	Change   int64
+	// HasChange is false when the leftover after Fee would be dust: it
+	// is folded into Fee instead of becoming its own output.
+	HasChange bool
+}
+
+// Select chooses a subset of available covering target at feeRate
+// (satoshis per vbyte), assuming a single P2TR change output of
+// changeScriptLen bytes if one ends up being worth creating. It
+// re-estimates the transaction's vsize after each candidate set -
+// accounting for every selected coin's own witness size - and reselects
+// if that changes whether a change output is affordable, converging
+// within maxSelectionPasses. Ties in the underlying strategy (multiple
+// candidate sets of equal waste) are broken by ascending outpoint order,
+// so the same available set always selects the same way.
+func Select(available []*UTXO, target int64, feeRate int64, changeScriptLen int, strategy Strategy) (SelectionResult, error) {
+	if target <= 0 {
+		return SelectionResult{}, errors.New("selection target must be positive")
+	}
+	if feeRate <= 0 {
+		return SelectionResult{}, errors.New("fee rate must be positive")
+	}
+	for _, u := range available {
+		if u == nil || u.Amount <= 0 {
+			return SelectionResult{}, errors.New("every candidate UTXO must have a positive amount")
+		}
+	}
+
+	ordered := sortCandidates(available, strategy)
+
+	// withChange starts true: the first pass assumes a change output
+	// might be needed, then Select drops it once it learns the exact
+	// leftover would be dust.
+	withChange := true
+	var (
+		selected []*UTXO
+		fee      int64
+	)
+	for pass := 0; pass < maxSelectionPasses; pass++ {
+		requiredFee := estimateFee(len(selected), withChange, changeScriptLen, feeRate, selected)
+		// Re-run selection against the updated requirement; a previous
+		// pass's selection is discarded rather than grown, since a
+		// smaller/larger fee can change which candidate set is optimal.
+		chosen, err := selectFor(ordered, target+requiredFee, strategy)
+		if err != nil {
+			return SelectionResult{}, err
+		}
+
+		actualFee := estimateFee(len(chosen), withChange, changeScriptLen, feeRate, chosen)
+		total := sumAmounts(chosen)
+		leftover := total - target - actualFee
+
+		if withChange && leftover < dustLimit {
+			// Change would be dust (or negative); drop it and reselect
+			// against the smaller no-change fee.
+			withChange = false
+			selected = chosen
+			fee = actualFee
+			continue
+		}
+
+		selected, fee = chosen, actualFee
+		break
+	}
+
+	total := sumAmounts(selected)
+	change := total - target - fee
+	if change < dustLimit {
+		return SelectionResult{
+			Selected:  selected,
+			Fee:       fee + change,
+			Change:    0,
+			HasChange: false,
+		}, nil
+	}
+
+	return SelectionResult{
+		Selected:  selected,
+		Fee:       fee,
+		Change:    change,
+		HasChange: true,
+	}, nil
+}
+
+// selectFor runs strategy against ordered and returns a subset whose
+// total reaches required: for LargestFirst and SingleRandomDraw the
+// shortest prefix of ordered that does, for BranchAndBound the subset
+// with the least excess over required (falling back to that prefix when
+// the search finds none).
+func selectFor(ordered []*UTXO, required int64, strategy Strategy) ([]*UTXO, error) {
+	switch strategy {
+	case LargestFirst, SingleRandomDraw:
+		return selectGreedy(ordered, required)
+	case BranchAndBound:
+		if found, ok := selectBranchAndBound(ordered, required); ok {
+			return found, nil
+		}
+		// No combination matches exactly within the search bound; fall
+		// back to the same greedy accumulation every other strategy
+		// uses, same as bitcoind does when branch-and-bound fails to
+		// converge.
+		return selectGreedy(ordered, required)
+	default:
+		return nil, errors.New("unknown coin selection strategy")
+	}
+}
+
+// selectGreedy accumulates ordered's coins in order until required is
+// covered.
+func selectGreedy(ordered []*UTXO, required int64) ([]*UTXO, error) {
+	var chosen []*UTXO
+	var total int64
+	for _, u := range ordered {
+		chosen = append(chosen, u)
+		total += u.Amount
+		if total >= required {
+			return chosen, nil
+		}
+	}
+	return nil, errors.New("insufficient funds to cover target and fees")
+}
+
+// maxBranchAndBoundNodes bounds the exhaustive include/exclude search
+// below, the same safeguard bitcoind's own branch-and-bound uses against
+// a combinatorial blowup on a large candidate set.
+const maxBranchAndBoundNodes = 100000
+
+// selectBranchAndBound searches for the subset of ordered whose total is
+// closest to (but not below) required, preferring an exact match so no
+// change output - and its own fee cost - is needed at all.
+func selectBranchAndBound(ordered []*UTXO, required int64) ([]*UTXO, bool) {
+	var (
+		best     []*UTXO
+		bestWaste int64 = -1
+		visited  int
+	)
+
+	var search func(i int, current []*UTXO, total int64)
+	search = func(i int, current []*UTXO, total int64) {
+		visited++
+		if visited > maxBranchAndBoundNodes {
+			return
+		}
+		if total >= required {
+			waste := total - required
+			if bestWaste < 0 || waste < bestWaste {
+				bestWaste = waste
+				best = append([]*UTXO(nil), current...)
+			}
+			return
+		}
+		if i >= len(ordered) {
+			return
+		}
+
+		// Include ordered[i] in a freshly copied slice (current may be
+		// shared with a sibling branch still in flight), then explore
+		// excluding it.
+		withI := make([]*UTXO, len(current)+1)
+		copy(withI, current)
+		withI[len(current)] = ordered[i]
+		search(i+1, withI, total+ordered[i].Amount)
+		search(i+1, current, total)
+	}
+	search(0, nil, 0)
+
+	return best, best != nil
+}
+
+// sortCandidates orders available for the given strategy: descending by
+// amount for LargestFirst (and as branch-and-bound's search order, since
+// trying the largest coins first finds a tight match sooner), by a
+// deterministic per-coin digest for SingleRandomDraw, and by outpoint
+// for BranchAndBound's own tie-break. In every case ties fall back to
+// ascending outpoint order.
+func sortCandidates(available []*UTXO, strategy Strategy) []*UTXO {
+	ordered := append([]*UTXO(nil), available...)
+
+	switch strategy {
+	case SingleRandomDraw:
+		sort.Slice(ordered, func(i, j int) bool {
+			return bytes.Compare(drawKey(ordered[i]), drawKey(ordered[j])) < 0
+		})
+	default:
+		sort.Slice(ordered, func(i, j int) bool {
+			if ordered[i].Amount != ordered[j].Amount {
+				return ordered[i].Amount > ordered[j].Amount
+			}
+			return compareOutpoints(ordered[i], ordered[j]) < 0
+		})
+	}
+	return ordered
+}
+
+// drawKey derives SingleRandomDraw's per-coin ordering key: the double
+// SHA-256 of the coin's outpoint, which spreads coins across the order
+// space the way a random draw would while staying a pure function of the
+// coin itself.
+func drawKey(u *UTXO) []byte {
+	op := u.outpoint()
+	digest := chainhash.DoubleHashB(append(op.Hash[:], byte(op.Index), byte(op.Index>>8), byte(op.Index>>16), byte(op.Index>>24)))
+	return digest
+}
+
+func compareOutpoints(a, b *UTXO) int {
+	opA, opB := a.outpoint(), b.outpoint()
+	if cmp := bytes.Compare(opA.Hash[:], opB.Hash[:]); cmp != 0 {
+		return cmp
+	}
+	if opA.Index < opB.Index {
+		return -1
+	}
+	if opA.Index > opB.Index {
+		return 1
+	}
+	return 0
+}
+
+func sumAmounts(utxos []*UTXO) int64 {
+	var total int64
+	for _, u := range utxos {
+		total += u.Amount
+	}
+	return total
+}
+
+// estimateFee sizes a transaction with numInputs inputs (each sized from
+// its own coin's witness, when coins is non-nil) and either one output
+// (no change) or two (with a changeScriptLen-byte change output), at
+// feeRate satoshis per vbyte, following the same BIP-141
+// weight-to-vsize formula estimateTxSize uses elsewhere in this module.
+func estimateFee(numInputs int, withChange bool, changeScriptLen int, feeRate int64, coins []*UTXO) int64 {
+	if numInputs == 0 {
+		numInputs = 1
+	}
+
+	// Base (non-witness) size: version(4) + locktime(4) + input/output
+	// count varints(2, assumed single-byte) + per-input outpoint(36) +
+	// empty scriptSig varint(1) + sequence(4) + per-output amount(8) +
+	// scriptPubKey varint+script. The single main output is sized as a
+	// P2TR output (8 + 1 + 34 bytes), and the optional change output from
+	// changeScriptLen.
+	baseSize := 4 + 4 + 2 + numInputs*41 + 8 + 1 + 34
+	witnessSize := 0
+	if len(coins) > 0 {
+		for _, u := range coins {
+			witnessSize += u.witnessSize()
+		}
+	} else {
+		witnessSize = numInputs * 64
+	}
+
+	if withChange {
+		baseSize += 8 + 1 + changeScriptLen
+	}
+
+	weight := baseSize*4 + witnessSize
+	vsize := (weight + 3) / 4
+	return int64(vsize) * feeRate
+}
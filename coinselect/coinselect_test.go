@@ -0,0 +1,103 @@
+package coinselect
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testUTXO(t *testing.T, amount int64, index uint32) *UTXO {
+	hash, err := chainhash.NewHashFromStr("0000000000000000000000000000000000000000000000000000000000000001")
+	require.NoError(t, err)
+	return &UTXO{TxHash: *hash, OutputIndex: index, Amount: amount}
+}
+
+func TestSelectBranchAndBoundPrefersLeastWaste(t *testing.T) {
+	available := []*UTXO{
+		testUTXO(t, 100000, 0),
+		testUTXO(t, 50200, 1),
+	}
+
+	result, err := Select(available, 50000, 1, 34, BranchAndBound)
+	require.NoError(t, err)
+	require.Len(t, result.Selected, 1, "the closer-matching coin should win over the larger one that needs sizeable change")
+	assert.Equal(t, int64(50200), result.Selected[0].Amount)
+	assert.False(t, result.HasChange, "leftover this small should be folded into the fee rather than becoming change")
+}
+
+func TestSelectLargestFirstMinimizesInputCount(t *testing.T) {
+	available := []*UTXO{
+		testUTXO(t, 10000, 0),
+		testUTXO(t, 10000, 1),
+		testUTXO(t, 100000, 2),
+	}
+
+	result, err := Select(available, 50000, 1, 34, LargestFirst)
+	require.NoError(t, err)
+	require.Len(t, result.Selected, 1)
+	assert.Equal(t, int64(100000), result.Selected[0].Amount)
+	assert.True(t, result.HasChange)
+}
+
+func TestSelectFoldsDustChangeIntoFee(t *testing.T) {
+	available := []*UTXO{testUTXO(t, 50300, 0)}
+
+	result, err := Select(available, 50000, 1, 34, LargestFirst)
+	require.NoError(t, err)
+	assert.False(t, result.HasChange, "leftover below dustLimit should be folded into the fee")
+	assert.Equal(t, int64(0), result.Change)
+	assert.Equal(t, sumAmounts(result.Selected)-50000, result.Fee, "the whole leftover becomes the fee")
+}
+
+func TestSelectInsufficientFunds(t *testing.T) {
+	available := []*UTXO{testUTXO(t, 1000, 0)}
+
+	_, err := Select(available, 50000, 1, 34, LargestFirst)
+	assert.Error(t, err)
+}
+
+func TestSelectRejectsInvalidInputs(t *testing.T) {
+	available := []*UTXO{testUTXO(t, 50000, 0)}
+
+	_, err := Select(available, 0, 1, 34, LargestFirst)
+	assert.Error(t, err, "target must be positive")
+
+	_, err = Select(available, 50000, 0, 34, LargestFirst)
+	assert.Error(t, err, "fee rate must be positive")
+
+	_, err = Select([]*UTXO{testUTXO(t, 0, 0)}, 50000, 1, 34, LargestFirst)
+	assert.Error(t, err, "every candidate must have a positive amount")
+}
+
+func TestSelectDeterministicAcrossInputOrder(t *testing.T) {
+	a := testUTXO(t, 50000, 0)
+	b := testUTXO(t, 30000, 1)
+	c := testUTXO(t, 20000, 2)
+
+	result1, err := Select([]*UTXO{a, b, c}, 70000, 1, 34, SingleRandomDraw)
+	require.NoError(t, err)
+	result2, err := Select([]*UTXO{c, b, a}, 70000, 1, 34, SingleRandomDraw)
+	require.NoError(t, err)
+
+	require.Equal(t, len(result1.Selected), len(result2.Selected))
+	for i := range result1.Selected {
+		assert.Equal(t, result1.Selected[i].OutputIndex, result2.Selected[i].OutputIndex)
+	}
+}
+
+func TestSelectAccountsForWitnessSize(t *testing.T) {
+	coin := testUTXO(t, 500000, 0)
+	coin.WitnessSize = 200
+
+	withScriptPathWitness, err := Select([]*UTXO{coin}, 99000, 10, 34, LargestFirst)
+	require.NoError(t, err)
+
+	coin.WitnessSize = 64
+	withKeyPathWitness, err := Select([]*UTXO{coin}, 99000, 10, 34, LargestFirst)
+	require.NoError(t, err)
+
+	assert.Greater(t, withScriptPathWitness.Fee, withKeyPathWitness.Fee,
+		"a bigger script-path witness should pay a bigger fee at the same fee rate")
+}
@@ -1,11 +1,11 @@
 package arkbuilders
 
 import (
-	"bytes"
 	"errors"
-	"sort"
 
 	"github.com/btcsuite/btcd/wire"
+
+	"github.com/utexo/ark-tx-builders/coinselect"
 )
 
 // BuildCommitmentTx creates a commitment transaction that batches VTXOs
@@ -24,6 +24,19 @@ func (tb *TxBuilder) BuildCommitmentTx(params *CommitmentTxParams) (*wire.MsgTx,
 	if params.BatchAmount <= 0 {
 		return nil, errors.New("batch amount must be positive")
 	}
+	if params.BatchAmount < DustLimit {
+		return nil, errors.New("batch amount below dust limit")
+	}
+	for _, utxo := range params.OperatorUTXOs {
+		if utxo.Amount <= 0 {
+			return nil, errors.New("operator UTXO amount must be positive")
+		}
+	}
+	for _, utxo := range params.BoardingOutputs {
+		if utxo.Amount <= 0 {
+			return nil, errors.New("boarding output amount must be positive")
+		}
+	}
 	// Use local variable to avoid mutating params
 	connectorAmount := params.ConnectorAmount
 	if connectorAmount < DustLimit {
@@ -36,8 +49,44 @@ func (tb *TxBuilder) BuildCommitmentTx(params *CommitmentTxParams) (*wire.MsgTx,
 	// Create new transaction with deterministic fields
 	tx := newDeterministicTx(TxVersion, 0)
 
+	// Build the anchor output (if any) up front, both so its value is
+	// folded into operatorTarget below (unlike an AnchorEphemeral
+	// anchor's zero, it's a real draw on the operator's inputs) and so
+	// it's accounted for in the funding check further down rather than
+	// appended on top of it.
+	anchorPolicy := effectiveAnchorPolicy(params.AnchorPolicy, params.UseEphemeralAnchor)
+	anchorOutput, err := buildAnchorOutput(anchorPolicy, params.BumpKey)
+	if err != nil {
+		return nil, err
+	}
+	var anchorValue int64
+	if anchorOutput != nil {
+		anchorValue = anchorOutput.Value
+	}
+
+	// Select only as many operator UTXOs as are needed to cover the
+	// batch and connector outputs (less whatever the boarding outputs
+	// already contribute), rather than spending every operator UTXO
+	// passed in and burning the rest as fee. Boarding outputs are always
+	// swept in full below; they aren't candidates for selection.
+	boardingTotal := int64(0)
+	for _, utxo := range params.BoardingOutputs {
+		boardingTotal += utxo.Amount
+	}
+	operatorTarget := params.BatchAmount + connectorAmount + anchorValue - boardingTotal
+	if operatorTarget < 1 {
+		// Boarding outputs alone already cover the outputs; Select still
+		// needs a positive target, and at least one operator UTXO is
+		// required below, so ask it to cover a nominal 1 satoshi of fee.
+		operatorTarget = 1
+	}
+	operatorSelection, err := coinselect.Select(toCoinselectUTXOs(params.OperatorUTXOs), operatorTarget, params.FeeRate, 0, params.OperatorUTXOStrategy)
+	if err != nil {
+		return nil, errors.New("insufficient input amount to cover outputs and fees")
+	}
+
 	// Add operator UTXO inputs first (deterministic ordering)
-	for _, utxo := range params.OperatorUTXOs {
+	for _, utxo := range operatorSelection.Selected {
 		txIn := wire.NewTxIn(
 			wire.NewOutPoint(&utxo.TxHash, utxo.OutputIndex),
 			nil,
@@ -59,7 +108,9 @@ func (tb *TxBuilder) BuildCommitmentTx(params *CommitmentTxParams) (*wire.MsgTx,
 	}
 
 	// Sort inputs for deterministic ordering
-	sortTxInputs(tx)
+	if tb.CanonicalOrdering {
+		sortTxInputs(tx)
+	}
 
 	// Build Batch output (Output 1)
 	// Path 1: Sweep - operator can claim after batch expiry
@@ -114,18 +165,26 @@ func (tb *TxBuilder) BuildCommitmentTx(params *CommitmentTxParams) (*wire.MsgTx,
 	// Add connector output (must be second)
 	tx.AddTxOut(wire.NewTxOut(connectorAmount, connectorTaprootScript))
 
-	// Verify we have enough inputs to cover outputs + fees
+	// Verify we have enough inputs to cover outputs + fees + the anchor's
+	// own value. When params.AnchorPolicy is explicitly set, size at
+	// anchorFeeRate instead of params.FeeRate: the parent's own fee is
+	// deferred to the CPFP child that spends the anchor. The legacy
+	// UseEphemeralAnchor field keeps sizing at the full params.FeeRate,
+	// matching its pre-AnchorPolicy behavior.
 	totalInput := int64(0)
-	for _, utxo := range params.OperatorUTXOs {
-		totalInput += utxo.Amount
-	}
-	for _, utxo := range params.BoardingOutputs {
+	for _, utxo := range operatorSelection.Selected {
 		totalInput += utxo.Amount
 	}
-
-	totalOutput := params.BatchAmount + connectorAmount
+	totalInput += boardingTotal
+
+	// feeSizingPolicy deliberately reads params.AnchorPolicy rather than
+	// the merged anchorPolicy above: UseEphemeralAnchor alone keeps
+	// sizing at the full params.FeeRate (see the comment above), so only
+	// an explicitly-set AnchorPolicy should reduce it.
+	feeSizingPolicy := params.AnchorPolicy
+	totalOutput := params.BatchAmount + connectorAmount + anchorValue
 	estimatedSize := estimateTxSize(tx, len(tx.TxIn), 0)
-	fee := estimatedSize * params.FeeRate
+	fee := estimatedSize * anchorFeeRate(feeSizingPolicy, params.FeeRate)
 
 	if totalInput < totalOutput+fee {
 		return nil, errors.New("insufficient input amount to cover outputs and fees")
@@ -134,22 +193,24 @@ func (tb *TxBuilder) BuildCommitmentTx(params *CommitmentTxParams) (*wire.MsgTx,
 	// Note: Outputs are already in correct order (batch first, connector second)
 	// No sorting needed to maintain deterministic order
 
-	return tx, nil
-}
-
-// sortTxInputs sorts transaction inputs deterministically
-// Sorts by txid (hash) first, then by output index
-func sortTxInputs(tx *wire.MsgTx) {
-	sort.Slice(tx.TxIn, func(i, j int) bool {
-		// Compare transaction hashes
-		cmp := bytes.Compare(
-			tx.TxIn[i].PreviousOutPoint.Hash[:],
-			tx.TxIn[j].PreviousOutPoint.Hash[:],
-		)
-		if cmp != 0 {
-			return cmp < 0
+	// Attach the memo before the anchor, so the anchor output (if any)
+	// stays last regardless of whether a memo is present.
+	if len(params.Memo) > 0 {
+		memoOutput, err := buildMemoOutput(params.Memo)
+		if err != nil {
+			return nil, err
 		}
-		// If hashes are equal, compare output indices
-		return tx.TxIn[i].PreviousOutPoint.Index < tx.TxIn[j].PreviousOutPoint.Index
-	})
+		tx.AddTxOut(memoOutput)
+	}
+
+	// Opt into TRUC and append the anchor output last, after the fee
+	// check and memo above, so its vbytes never factor into this tx's
+	// own fee rate: a CPFP child pays for it via
+	// BuildAnchorSpend/BuildBumpTx instead.
+	if anchorOutput != nil {
+		tx.Version = TxVersionTRUC
+		tx.AddTxOut(anchorOutput)
+	}
+
+	return tx, nil
 }
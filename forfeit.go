@@ -36,6 +36,10 @@ func (tb *TxBuilder) BuildForfeitTx(params *ForfeitTxParams) (*wire.MsgTx, error
 	// Create new transaction with deterministic fields
 	tx := newDeterministicTx(TxVersion, 0)
 
+	// Inputs keep this fixed [vtxo, anchor] order regardless of
+	// TxBuilder.CanonicalOrdering: BuildForfeitPSBT addresses them as
+	// packet.Inputs[0] and [1] by convention (see bip69.go).
+
 	// Add VTXO input (first)
 	vtxoIn := wire.NewTxIn(
 		wire.NewOutPoint(&params.VTXO.TxHash, params.VTXO.OutputIndex),
@@ -66,12 +70,33 @@ func (tb *TxBuilder) BuildForfeitTx(params *ForfeitTxParams) (*wire.MsgTx, error
 		return nil, err
 	}
 
-	// Calculate output amount (inputs - fee)
+	// Build the anchor output (if any) up front so its value, unlike an
+	// AnchorEphemeral anchor's zero, comes out of the operator output
+	// rather than being created on top of the available input amount.
+	anchorPolicy := effectiveAnchorPolicy(params.AnchorPolicy, params.UseEphemeralAnchor)
+	anchorOutput, err := buildAnchorOutput(anchorPolicy, params.BumpKey)
+	if err != nil {
+		return nil, err
+	}
+	var anchorValue int64
+	if anchorOutput != nil {
+		anchorValue = anchorOutput.Value
+	}
+
+	// Calculate output amount (inputs - fee - anchor value). When
+	// params.AnchorPolicy is explicitly set, size at anchorFeeRate
+	// instead of params.FeeRate: the parent's own fee is deferred to the
+	// CPFP child that spends the anchor. The legacy UseEphemeralAnchor
+	// field keeps sizing at the full params.FeeRate, matching its
+	// pre-AnchorPolicy behavior; feeSizingPolicy deliberately reads
+	// params.AnchorPolicy rather than the merged anchorPolicy above for
+	// that reason.
+	feeSizingPolicy := params.AnchorPolicy
 	totalInput := params.VTXO.Amount + params.ConnectorAnchor.Amount
 	estimatedSize := estimateTxSize(tx, 2, 0) // 2 inputs
-	fee := estimatedSize * params.FeeRate
+	fee := estimatedSize * anchorFeeRate(feeSizingPolicy, params.FeeRate)
 
-	outputAmount := totalInput - fee
+	outputAmount := totalInput - fee - anchorValue
 	if outputAmount <= 0 {
 		return nil, errors.New("insufficient input amount to cover fees")
 	}
@@ -79,6 +104,23 @@ func (tb *TxBuilder) BuildForfeitTx(params *ForfeitTxParams) (*wire.MsgTx, error
 	// Add single operator output
 	tx.AddTxOut(wire.NewTxOut(outputAmount, taprootScript))
 
+	// Attach the memo before the anchor, so the anchor output (if any)
+	// stays last regardless of whether a memo is present.
+	if len(params.Memo) > 0 {
+		memoOutput, err := buildMemoOutput(params.Memo)
+		if err != nil {
+			return nil, err
+		}
+		tx.AddTxOut(memoOutput)
+	}
+
+	// Opt into TRUC and append the anchor output last, after the fee
+	// calculation and memo above.
+	if anchorOutput != nil {
+		tx.Version = TxVersionTRUC
+		tx.AddTxOut(anchorOutput)
+	}
+
 	return tx, nil
 }
 
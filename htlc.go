@@ -0,0 +1,82 @@
+package arkbuilders
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// htlcSuccessWeight and htlcTimeoutWeight bias BuildHuffmanTapscriptTree's
+// leaf ordering toward the cooperative success path. With exactly the two
+// leaves BuildTaprootHTLC builds, a Huffman tree always has a single level,
+// so both leaves end up at the same depth regardless of these weights;
+// they're kept so a future third leaf (e.g. a second timeout tier) would
+// still favor the success path without revisiting this call site.
+const (
+	htlcSuccessWeight = 10
+	htlcTimeoutWeight = 1
+)
+
+// BuildTaprootHTLC builds a P2TR HTLC in the style of lnd loop's HtlcV3:
+// the internal key is the MuSig2 aggregate of sender and receiver, so the
+// cooperative case is a pure key-path spend with no script reveal, while
+// a success leaf (preimage reveal) and a timeout leaf (sender refund
+// after cltvExpiry) are available as script-path fallbacks.
+func BuildTaprootHTLC(senderKey, receiverKey *btcec.PublicKey, paymentHash [32]byte, cltvExpiry uint32) (*TaprootSpendInfo, []byte, error) {
+	aggKey, err := MuSig2AggregateKeys(senderKey, receiverKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	successScript, err := buildHTLCSuccessScript(receiverKey, paymentHash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timeoutScript, err := BuildCheckSigWithAbsTimelockScript(senderKey, cltvExpiry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := BuildHuffmanTapscriptTree(aggKey, []TapLeaf{
+		{Script: successScript, Weight: htlcSuccessWeight},
+		{Script: timeoutScript, Weight: htlcTimeoutWeight},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return info, info.ScriptPubKey(), nil
+}
+
+// buildHTLCSuccessScript builds the preimage-reveal leaf:
+//
+//	<receiverKey> OP_CHECKSIGVERIFY OP_SIZE <32> OP_EQUALVERIFY OP_SHA256 <paymentHash> OP_EQUALVERIFY OP_1
+func buildHTLCSuccessScript(receiverKey *btcec.PublicKey, paymentHash [32]byte) ([]byte, error) {
+	return txscript.NewScriptBuilder().
+		AddData(schnorr.SerializePubKey(receiverKey)).
+		AddOp(txscript.OP_CHECKSIGVERIFY).
+		AddOp(txscript.OP_SIZE).
+		AddInt64(32).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_SHA256).
+		AddData(paymentHash[:]).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_1).
+		Script()
+}
+
+// TaprootHTLCSuccessWitness assembles the script-path witness for the
+// receiver claiming the HTLC with the payment preimage:
+// signature, preimage, success script, control block.
+func TaprootHTLCSuccessWitness(signature, preimage, script, controlBlock []byte) wire.TxWitness {
+	return AssembleTaprootScriptWitness([][]byte{signature, preimage}, script, controlBlock)
+}
+
+// TaprootHTLCTimeoutWitness assembles the script-path witness for the
+// sender reclaiming the HTLC after cltvExpiry: signature, timeout
+// script, control block.
+func TaprootHTLCTimeoutWitness(signature, script, controlBlock []byte) wire.TxWitness {
+	return AssembleTaprootScriptWitness([][]byte{signature}, script, controlBlock)
+}
@@ -0,0 +1,40 @@
+package arkbuilders
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildTaprootHTLC verifies the HTLC's spend info exposes working
+// control blocks for both the success and timeout leaves.
+func TestBuildTaprootHTLC(t *testing.T) {
+	senderKey := createTestPrivKey(t, 0x01).PubKey()
+	receiverKey := createTestPrivKey(t, 0x02).PubKey()
+	preimage := []byte("super secret preimage value!!!!")
+	paymentHash := sha256.Sum256(preimage)
+
+	info, scriptPubKey, err := BuildTaprootHTLC(senderKey, receiverKey, paymentHash, 800000)
+	require.NoError(t, err)
+	require.NotEmpty(t, scriptPubKey)
+	require.Equal(t, info.ScriptPubKey(), scriptPubKey)
+
+	successScript, err := buildHTLCSuccessScript(receiverKey, paymentHash)
+	require.NoError(t, err)
+	timeoutScript, err := BuildCheckSigWithAbsTimelockScript(senderKey, 800000)
+	require.NoError(t, err)
+
+	successCB, err := info.ControlBlock(successScript)
+	require.NoError(t, err)
+	timeoutCB, err := info.ControlBlock(timeoutScript)
+	require.NoError(t, err)
+	require.NotEmpty(t, successCB)
+	require.NotEmpty(t, timeoutCB)
+
+	witness := TaprootHTLCSuccessWitness([]byte("sig"), preimage, successScript, successCB)
+	require.Len(t, witness, 4)
+
+	timeoutWitness := TaprootHTLCTimeoutWitness([]byte("sig"), timeoutScript, timeoutCB)
+	require.Len(t, timeoutWitness, 3)
+}
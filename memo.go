@@ -0,0 +1,31 @@
+package arkbuilders
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// MaxMemoSize is the largest OP_RETURN payload a memo output may carry.
+const MaxMemoSize = 80
+
+// buildMemoOutput wraps memo in a standard zero-value OP_RETURN output.
+// It is always appended last by the builders, after BIP-69 sorting, so it
+// never perturbs the deterministic ordering of the batch/connector/change
+// outputs the rest of the package relies on.
+func buildMemoOutput(memo []byte) (*wire.TxOut, error) {
+	if len(memo) > MaxMemoSize {
+		return nil, errors.New("memo exceeds maximum OP_RETURN payload size")
+	}
+
+	script, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_RETURN).
+		AddData(memo).
+		Script()
+	if err != nil {
+		return nil, err
+	}
+
+	return wire.NewTxOut(0, script), nil
+}
@@ -0,0 +1,489 @@
+package arkbuilders
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// MuSig2SecNonce holds the two secret nonce scalars generated by NonceGen.
+// It must never be reused across sessions/messages.
+type MuSig2SecNonce struct {
+	K1 btcec.ModNScalar
+	K2 btcec.ModNScalar
+}
+
+// MuSig2PubNonce is the pair of public nonce points (R1, R2) a signer
+// broadcasts to the other participants.
+type MuSig2PubNonce struct {
+	R1 *btcec.PublicKey
+	R2 *btcec.PublicKey
+}
+
+// MuSig2AggNonce is the componentwise aggregation of every signer's
+// MuSig2PubNonce, per BIP-327 NonceAgg.
+type MuSig2AggNonce struct {
+	R1 *btcec.PublicKey
+	R2 *btcec.PublicKey
+}
+
+// MuSig2SessionContext binds the aggregated key (with any taproot tweak
+// applied), the message, and the aggregated nonces into the values needed
+// to produce and verify partial signatures: the effective nonce R, the
+// nonce coefficient b, and the BIP-340 challenge e.
+type MuSig2SessionContext struct {
+	AggPubKey    *btcec.PublicKey
+	OutputKey    *btcec.PublicKey // AggPubKey after the taproot tweak
+	OutputParity bool             // true if OutputKey has odd Y
+	Tweak        *btcec.ModNScalar
+	Msg          []byte
+	AggNonce     *MuSig2AggNonce
+	B            btcec.ModNScalar // nonce coefficient
+	R            *btcec.PublicKey // effective nonce point, even-Y
+	RNegated     bool             // true if R1/R2 must be negated to reach even-Y R
+	E            btcec.ModNScalar // BIP-340 challenge
+}
+
+// MuSig2Session represents one signer's participation in a BIP-327 signing
+// session: it aggregates the participant set, derives this signer's
+// key-aggregation coefficient, generates/holds the secret nonce, and
+// produces the partial signature once every pubnonce has been collected.
+type MuSig2Session struct {
+	privKey     *btcec.PrivateKey
+	pubKeys     []*btcec.PublicKey
+	aggPubKey   *btcec.PublicKey
+	coefficient btcec.ModNScalar
+
+	secNonce *MuSig2SecNonce
+	pubNonce *MuSig2PubNonce
+
+	// msg and tweak are only set by NewSession, and ctx only by
+	// RegisterNonces (see musig2_session.go), for the higher-level
+	// fixed-message signing flow (PublicNonce/RegisterNonces/Sign/
+	// CombinePartialSigs).
+	// Sessions built via NewMuSig2Session and driven directly through
+	// NonceGen/PartialSign leave them nil.
+	msg   []byte
+	tweak []byte
+	ctx   *MuSig2SessionContext
+}
+
+// NewMuSig2Session creates a signing session for privKey, aggregating it
+// together with the other participants' public keys. The key-aggregation
+// coefficient for this signer is computed once here (the same coefficient
+// MuSig2AggregateKeys computes internally) so PartialSign never has to
+// recompute it.
+func NewMuSig2Session(privKey *btcec.PrivateKey, otherPubs ...*btcec.PublicKey) (*MuSig2Session, error) {
+	if privKey == nil {
+		return nil, errors.New("private key is required")
+	}
+
+	pubKeys := append([]*btcec.PublicKey{privKey.PubKey()}, otherPubs...)
+
+	aggPubKey, err := MuSig2AggregateKeys(pubKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	coefficient, err := muSig2KeyAggCoefficient(pubKeys, privKey.PubKey())
+	if err != nil {
+		return nil, err
+	}
+
+	return &MuSig2Session{
+		privKey:     privKey,
+		pubKeys:     pubKeys,
+		aggPubKey:   aggPubKey,
+		coefficient: *coefficient,
+	}, nil
+}
+
+// AggregatePubKey returns the MuSig2-aggregated public key for this
+// session's participant set, before any taproot tweak.
+func (s *MuSig2Session) AggregatePubKey() *btcec.PublicKey {
+	return s.aggPubKey
+}
+
+// Coefficient returns this signer's key-aggregation coefficient a_i, so
+// callers that need it for PartialSigVerify don't have to recompute it.
+func (s *MuSig2Session) Coefficient() btcec.ModNScalar {
+	return s.coefficient
+}
+
+// NonceGen implements the BIP-327 §4.2 nonce generation algorithm,
+// deriving two secret nonce scalars (k1, k2) from rand, the signer's
+// secret key, the aggregated key, the message, and an optional extra_in.
+// The resulting MuSig2SecNonce is stored on the session and must be used
+// for exactly one PartialSign call.
+func (s *MuSig2Session) NonceGen(rand [32]byte, msg []byte, extraIn []byte) (*MuSig2PubNonce, error) {
+	secNonce, err := muSig2NonceGen(rand, s.privKey, s.aggPubKey, msg, extraIn)
+	if err != nil {
+		return nil, err
+	}
+	s.secNonce = secNonce
+
+	var r1Point, r2Point btcec.JacobianPoint
+	btcec.ScalarBaseMultNonConst(&secNonce.K1, &r1Point)
+	btcec.ScalarBaseMultNonConst(&secNonce.K2, &r2Point)
+	r1Point.ToAffine()
+	r2Point.ToAffine()
+
+	pubNonce := &MuSig2PubNonce{
+		R1: btcec.NewPublicKey(&r1Point.X, &r1Point.Y),
+		R2: btcec.NewPublicKey(&r2Point.X, &r2Point.Y),
+	}
+	s.pubNonce = pubNonce
+
+	return pubNonce, nil
+}
+
+// NonceGenDeterministic is a deterministic variant of NonceGen for tests
+// and replayable signing flows: it uses a fixed all-zero rand value so the
+// same (privKey, aggPubKey, msg, extraIn) always yields the same nonces.
+func (s *MuSig2Session) NonceGenDeterministic(msg []byte, extraIn []byte) (*MuSig2PubNonce, error) {
+	var zeroRand [32]byte
+	return s.NonceGen(zeroRand, msg, extraIn)
+}
+
+// muSig2NonceGen derives the two secret nonce scalars for a signer. Each
+// scalar is the result of a tagged hash over the signer's rand, secret
+// key, aggregate public key, message and extra_in, domain-separated by a
+// trailing index byte, mirroring BIP-327 NonceGen without the optional-arg
+// plumbing that spec allows.
+func muSig2NonceGen(rand [32]byte, privKey *btcec.PrivateKey, aggPubKey *btcec.PublicKey, msg []byte, extraIn []byte) (*MuSig2SecNonce, error) {
+	if privKey == nil {
+		return nil, errors.New("private key is required for nonce generation")
+	}
+	if aggPubKey == nil {
+		return nil, errors.New("aggregated public key is required for nonce generation")
+	}
+
+	base := func(index byte) btcec.ModNScalar {
+		var buf bytes.Buffer
+		buf.Write(rand[:])
+		buf.Write(privKey.Serialize())
+		buf.Write(schnorr.SerializePubKey(aggPubKey))
+		buf.Write(msg)
+		buf.Write(extraIn)
+		buf.WriteByte(index)
+
+		digest := taggedHash("MuSig/nonce", buf.Bytes())
+
+		var scalar btcec.ModNScalar
+		scalar.SetByteSlice(digest)
+		return scalar
+	}
+
+	k1 := base(0x00)
+	k2 := base(0x01)
+
+	return &MuSig2SecNonce{K1: k1, K2: k2}, nil
+}
+
+// NonceAgg aggregates every signer's public nonce pair into a single
+// MuSig2AggNonce via componentwise point addition of each (R1_i, R2_i).
+func NonceAgg(pubNonces []*MuSig2PubNonce) (*MuSig2AggNonce, error) {
+	if len(pubNonces) == 0 {
+		return nil, errors.New("at least one public nonce is required")
+	}
+
+	var aggR1, aggR2 btcec.JacobianPoint
+	aggR1.X.SetInt(0)
+	aggR1.Y.SetInt(0)
+	aggR1.Z.SetInt(0)
+	aggR2.X.SetInt(0)
+	aggR2.Y.SetInt(0)
+	aggR2.Z.SetInt(0)
+
+	for _, pn := range pubNonces {
+		var r1, r2 btcec.JacobianPoint
+		pn.R1.AsJacobian(&r1)
+		pn.R2.AsJacobian(&r2)
+
+		btcec.AddNonConst(&aggR1, &r1, &aggR1)
+		btcec.AddNonConst(&aggR2, &r2, &aggR2)
+	}
+
+	aggR1.ToAffine()
+	aggR2.ToAffine()
+
+	return &MuSig2AggNonce{
+		R1: btcec.NewPublicKey(&aggR1.X, &aggR1.Y),
+		R2: btcec.NewPublicKey(&aggR2.X, &aggR2.Y),
+	}, nil
+}
+
+// NewMuSig2SessionContext binds the aggregated key, an optional x-only
+// taproot tweak, the message, and the aggregated nonces into a
+// MuSig2SessionContext. It computes the effective nonce R = R1 + b*R2
+// (b = H_noncecoef(aggnonces, Q, m)) and the BIP-340 challenge
+// e = H_sig(R || Q || m), applying the even-Y convention with parity
+// flipping for both the tweaked key Q and the effective nonce R.
+func NewMuSig2SessionContext(aggPubKey *btcec.PublicKey, tweak []byte, msg []byte, aggNonce *MuSig2AggNonce) (*MuSig2SessionContext, error) {
+	if aggPubKey == nil {
+		return nil, errors.New("aggregated public key is required")
+	}
+	if aggNonce == nil {
+		return nil, errors.New("aggregated nonce is required")
+	}
+
+	outputKey, outputParity, tweakScalar, err := taprootTweakPubKeyScalar(aggPubKey, tweak)
+	if err != nil {
+		return nil, err
+	}
+
+	outputKeyXOnly := schnorr.SerializePubKey(outputKey)
+
+	var bBuf bytes.Buffer
+	bBuf.Write(schnorr.SerializePubKey(aggNonce.R1))
+	bBuf.Write(schnorr.SerializePubKey(aggNonce.R2))
+	bBuf.Write(outputKeyXOnly)
+	bBuf.Write(msg)
+	bDigest := taggedHash("MuSig/noncecoef", bBuf.Bytes())
+
+	var b btcec.ModNScalar
+	b.SetByteSlice(bDigest)
+
+	var r1, r2, bR2, rPoint btcec.JacobianPoint
+	aggNonce.R1.AsJacobian(&r1)
+	aggNonce.R2.AsJacobian(&r2)
+	btcec.ScalarMultNonConst(&b, &r2, &bR2)
+	btcec.AddNonConst(&r1, &bR2, &rPoint)
+	rPoint.ToAffine()
+
+	rNegated := rPoint.Y.IsOdd()
+	if rNegated {
+		rPoint.Y.Negate(1)
+		rPoint.Y.Normalize()
+	}
+	rEven := btcec.NewPublicKey(&rPoint.X, &rPoint.Y)
+
+	var eBuf bytes.Buffer
+	eBuf.Write(schnorr.SerializePubKey(rEven))
+	eBuf.Write(outputKeyXOnly)
+	eBuf.Write(msg)
+	eDigest := taggedHash("BIP0340/challenge", eBuf.Bytes())
+
+	var e btcec.ModNScalar
+	e.SetByteSlice(eDigest)
+
+	return &MuSig2SessionContext{
+		AggPubKey:    aggPubKey,
+		OutputKey:    outputKey,
+		OutputParity: outputParity,
+		Tweak:        tweakScalar,
+		Msg:          msg,
+		AggNonce:     aggNonce,
+		B:            b,
+		R:            rEven,
+		RNegated:     rNegated,
+		E:            e,
+	}, nil
+}
+
+// PartialSign produces this signer's partial signature
+// s_i = k1_i + b*k2_i + e*a_i*x_i, where x_i is this signer's (possibly
+// negated) secret key so that it corresponds to the even-Y public key in
+// the aggregate, with k1_i/k2_i negated to match the even-Y effective
+// nonce R. ctx must have been built from nonces that include this
+// session's PubNonce.
+func (s *MuSig2Session) PartialSign(ctx *MuSig2SessionContext) ([32]byte, error) {
+	var zero [32]byte
+	if s.secNonce == nil {
+		return zero, errors.New("nonce must be generated before signing")
+	}
+	if ctx == nil {
+		return zero, errors.New("session context is required")
+	}
+
+	k1, k2 := s.secNonce.K1, s.secNonce.K2
+	if ctx.RNegated {
+		k1.Negate()
+		k2.Negate()
+	}
+
+	// x_i must correspond to the even-Y public key actually used in the
+	// aggregate, and be negated again if the final output key is odd-Y.
+	// MuSig2AggregateKeys sums each participant's point with whatever
+	// parity it naturally has, so the bit to check here is the
+	// pre-tweak aggregate key's parity, not this signer's own key's.
+	d := s.privKey.Key
+	var aggPubKeyPoint btcec.JacobianPoint
+	s.aggPubKey.AsJacobian(&aggPubKeyPoint)
+	aggPubKeyPoint.ToAffine()
+	if aggPubKeyPoint.Y.IsOdd() {
+		d.Negate()
+	}
+	if ctx.OutputParity {
+		d.Negate()
+	}
+
+	var bk2 btcec.ModNScalar
+	bk2.Mul2(&ctx.B, &k2)
+
+	var eCoeff btcec.ModNScalar
+	eCoeff.Mul2(&ctx.E, &s.coefficient)
+	eCoeff.Mul(&d)
+
+	var sig btcec.ModNScalar
+	sig.Set(&k1)
+	sig.Add(&bk2)
+	sig.Add(&eCoeff)
+
+	var out [32]byte
+	sig.PutBytesUnchecked(out[:])
+	return out, nil
+}
+
+// PartialSigVerify checks a single signer's partial signature against
+// s_i*G == R_i,1 + b*R_i,2 + e*a_i*P_i, using that signer's broadcast
+// pubNonce and public key plus their key-aggregation coefficient.
+func PartialSigVerify(ctx *MuSig2SessionContext, pubNonce *MuSig2PubNonce, pubKey *btcec.PublicKey, coefficient *btcec.ModNScalar, partialSig [32]byte) (bool, error) {
+	if ctx == nil || pubNonce == nil || pubKey == nil || coefficient == nil {
+		return false, errors.New("session context, pubnonce, pubkey and coefficient are all required")
+	}
+
+	var s btcec.ModNScalar
+	if overflow := s.SetBytes(&partialSig); overflow != 0 {
+		return false, errors.New("partial signature is not a valid scalar")
+	}
+
+	var lhs btcec.JacobianPoint
+	btcec.ScalarBaseMultNonConst(&s, &lhs)
+	lhs.ToAffine()
+
+	r1, r2 := pubNonce.R1, pubNonce.R2
+	if ctx.RNegated {
+		var negR1, negR2 btcec.JacobianPoint
+		r1.AsJacobian(&negR1)
+		r2.AsJacobian(&negR2)
+		negR1.ToAffine()
+		negR2.ToAffine()
+		negR1.Y.Negate(1)
+		negR1.Y.Normalize()
+		negR2.Y.Negate(1)
+		negR2.Y.Normalize()
+		r1 = btcec.NewPublicKey(&negR1.X, &negR1.Y)
+		r2 = btcec.NewPublicKey(&negR2.X, &negR2.Y)
+	}
+
+	var r1Point, r2Point, bR2, rhsR btcec.JacobianPoint
+	r1.AsJacobian(&r1Point)
+	r2.AsJacobian(&r2Point)
+	btcec.ScalarMultNonConst(&ctx.B, &r2Point, &bR2)
+	btcec.AddNonConst(&r1Point, &bR2, &rhsR)
+
+	var eCoeff btcec.ModNScalar
+	eCoeff.Mul2(&ctx.E, coefficient)
+
+	// P_i must correspond to the even-Y public key actually used in the
+	// aggregate, and be negated again if the final output key is odd-Y.
+	// MuSig2AggregateKeys sums each participant's point with whatever
+	// parity it naturally has, so the bit to check here is the
+	// pre-tweak aggregate key's parity, not this signer's own key's.
+	pubKeyPoint := new(btcec.JacobianPoint)
+	pubKey.AsJacobian(pubKeyPoint)
+	pubKeyPoint.ToAffine()
+	var aggPubKeyPoint btcec.JacobianPoint
+	ctx.AggPubKey.AsJacobian(&aggPubKeyPoint)
+	aggPubKeyPoint.ToAffine()
+	if aggPubKeyPoint.Y.IsOdd() {
+		pubKeyPoint.Y.Negate(1)
+		pubKeyPoint.Y.Normalize()
+	}
+	if ctx.OutputParity {
+		pubKeyPoint.Y.Negate(1)
+		pubKeyPoint.Y.Normalize()
+	}
+
+	var ePPoint btcec.JacobianPoint
+	btcec.ScalarMultNonConst(&eCoeff, pubKeyPoint, &ePPoint)
+
+	var rhs btcec.JacobianPoint
+	btcec.AddNonConst(&rhsR, &ePPoint, &rhs)
+	rhs.ToAffine()
+
+	return lhs.X.Equals(&rhs.X) && lhs.Y.Equals(&rhs.Y), nil
+}
+
+// PartialSigAgg sums every participant's partial signature into a single
+// scalar s = Σ s_i + e*g*tweak (mod n) and pairs it with the session's
+// effective nonce R to produce the final BIP-340 Schnorr signature on the
+// aggregated (tweaked) output key. Per BIP-327's tweaking extension, the
+// tweak contribution is added exactly once here rather than by each
+// signer in PartialSign, since every signer's ctx (and thus e) is
+// identical and summing a per-signer term would multiply it by the
+// number of signers. g flips the tweak's sign to match the even-Y
+// convention applied to the output key.
+func PartialSigAgg(ctx *MuSig2SessionContext, partialSigs [][32]byte) (*schnorr.Signature, error) {
+	if ctx == nil {
+		return nil, errors.New("session context is required")
+	}
+	if len(partialSigs) == 0 {
+		return nil, errors.New("at least one partial signature is required")
+	}
+
+	var s btcec.ModNScalar
+	for _, ps := range partialSigs {
+		var si btcec.ModNScalar
+		if overflow := si.SetBytes(&ps); overflow != 0 {
+			return nil, errors.New("partial signature is not a valid scalar")
+		}
+		s.Add(&si)
+	}
+
+	if ctx.Tweak != nil {
+		var eTweak btcec.ModNScalar
+		eTweak.Set(ctx.Tweak)
+		if ctx.OutputParity {
+			eTweak.Negate()
+		}
+		eTweak.Mul(&ctx.E)
+		s.Add(&eTweak)
+	}
+
+	rXOnly := schnorr.SerializePubKey(ctx.R)
+	var sigBytes [64]byte
+	copy(sigBytes[:32], rXOnly)
+	s.PutBytesUnchecked(sigBytes[32:])
+
+	return schnorr.ParseSignature(sigBytes[:])
+}
+
+// muSig2KeyAggCoefficient recomputes the BIP-327 key-aggregation
+// coefficient a_i = H(L || Pi) for signerKey within pubKeys, using the
+// same key-list hash MuSig2AggregateKeys derives internally so the two
+// never drift apart.
+func muSig2KeyAggCoefficient(pubKeys []*btcec.PublicKey, signerKey *btcec.PublicKey) (*btcec.ModNScalar, error) {
+	if len(pubKeys) == 0 {
+		return nil, errors.New("at least one public key is required")
+	}
+
+	sortedKeys := make([]*btcec.PublicKey, len(pubKeys))
+	copy(sortedKeys, pubKeys)
+	sort.Slice(sortedKeys, func(i, j int) bool {
+		return bytes.Compare(
+			schnorr.SerializePubKey(sortedKeys[i]),
+			schnorr.SerializePubKey(sortedKeys[j]),
+		) < 0
+	})
+
+	var keyListBuf bytes.Buffer
+	for _, pk := range sortedKeys {
+		keyListBuf.Write(schnorr.SerializePubKey(pk))
+	}
+	keyListHash := sha256.Sum256(keyListBuf.Bytes())
+
+	var coefBuf bytes.Buffer
+	coefBuf.Write(keyListHash[:])
+	coefBuf.Write(schnorr.SerializePubKey(signerKey))
+	coefHash := sha256.Sum256(coefBuf.Bytes())
+
+	var coeff btcec.ModNScalar
+	coeff.SetByteSlice(coefHash[:])
+	return &coeff, nil
+}
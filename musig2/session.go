@@ -0,0 +1,502 @@
+// Package musig2 provides a standalone BIP-327 MuSig2 cooperative signing
+// round that produces signatures verifying against a Taproot output key,
+// for use by callers of BuildBoardingTx and the unroll path of
+// BuildCommitmentTx, both of which aggregate keys via
+// arkbuilders.MuSig2AggregateKeys but otherwise leave producing the
+// aggregate signature to the caller.
+//
+// It is deliberately stateless where the parent module's MuSig2Session
+// isn't: every method takes the secret material it needs (secKey,
+// secNonce) as an argument rather than storing it on the session, so a
+// single Session can drive the nonce-aggregation and signature-combining
+// side of a round while each participant's secrets stay with that
+// participant.
+package musig2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	arkbuilders "github.com/utexo/ark-tx-builders"
+)
+
+// PubNonces is the pair of public nonce points (R1, R2) a signer
+// broadcasts to the other participants of a Session, per BIP-327
+// NonceGen.
+type PubNonces struct {
+	R1 *btcec.PublicKey
+	R2 *btcec.PublicKey
+}
+
+// SecNonces holds the two secret nonce scalars GenerateNonces derives.
+// It must never be reused across signing rounds or messages.
+type SecNonces struct {
+	K1 btcec.ModNScalar
+	K2 btcec.ModNScalar
+}
+
+// AggNonce is the componentwise aggregation of every participant's
+// PubNonces, per BIP-327 NonceAgg.
+type AggNonce struct {
+	R1 *btcec.PublicKey
+	R2 *btcec.PublicKey
+}
+
+// PartialSig is one signer's contribution to the round's aggregated
+// Schnorr signature, as produced by Session.PartialSign and summed by
+// Session.CombineSigs.
+type PartialSig [32]byte
+
+// roundContext binds an AggNonce and a sighash to the effective nonce R
+// and BIP-340 challenge e that PartialSign and CombineSigs both need, so
+// CombineSigs doesn't have to recompute them (or be handed the aggnonce
+// and sighash a second time) once at least one PartialSign call has run.
+type roundContext struct {
+	aggNonce AggNonce
+	sighash  []byte
+	b        btcec.ModNScalar
+	r        *btcec.PublicKey
+	rNegated bool
+	e        btcec.ModNScalar
+}
+
+// Session coordinates one BIP-327 MuSig2 signing round among
+// participants, producing partial signatures that combine into a
+// signature verifying against the Taproot output key taprootTweak tweaks
+// the aggregate key to (nil applies the BIP-341 key-path-only tweak with
+// an empty merkle root; CreateTaprootScript, given no scripts, instead
+// leaves its internal key untweaked).
+type Session struct {
+	participants []*btcec.PublicKey
+	aggPubKey    *btcec.PublicKey
+	outputKey    *btcec.PublicKey
+	outputParity bool
+	tweak        *btcec.ModNScalar
+
+	ctx *roundContext
+}
+
+// NewSession aggregates participants via arkbuilders.MuSig2AggregateKeys
+// and tweaks the result via arkbuilders.TaprootTweakPublicKey, so for a
+// non-nil taprootTweak the signatures this round produces verify against
+// the same output key CreateTaprootScript gives the aggregate key with
+// the script tree whose merkle root taprootTweak is.
+func NewSession(participants []*btcec.PublicKey, taprootTweak []byte) (*Session, error) {
+	if len(participants) == 0 {
+		return nil, errors.New("at least one participant public key is required")
+	}
+
+	aggPubKey, err := arkbuilders.MuSig2AggregateKeys(participants...)
+	if err != nil {
+		return nil, err
+	}
+
+	outputKey, outputParity, err := arkbuilders.TaprootTweakPublicKey(aggPubKey, taprootTweak)
+	if err != nil {
+		return nil, err
+	}
+
+	tweak, err := tapTweakScalar(aggPubKey, taprootTweak)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		participants: participants,
+		aggPubKey:    aggPubKey,
+		outputKey:    outputKey,
+		outputParity: outputParity,
+		tweak:        tweak,
+	}, nil
+}
+
+// AggregatePubKey returns the MuSig2-aggregated public key for this
+// session's participant set, before the taproot tweak.
+func (s *Session) AggregatePubKey() *btcec.PublicKey {
+	return s.aggPubKey
+}
+
+// OutputKey returns the Taproot output key partial signatures produced
+// by this session verify against, together with its parity bit.
+func (s *Session) OutputKey() (*btcec.PublicKey, bool) {
+	return s.outputKey, s.outputParity
+}
+
+// GenerateNonces implements the BIP-327 §4.2 nonce-generation algorithm
+// for one participant, deriving two secret nonce scalars from secret (a
+// caller-supplied seed) and this session's aggregated public key.
+// Passing a seed derived deterministically from the unsigned transaction
+// (e.g. sha256(signerPrivKey.Serialize(), tx.TxHash()) as extra_input)
+// makes the result reproducible, so tests can replay a signing round
+// without needing real randomness.
+func (s *Session) GenerateNonces(secret []byte) (PubNonces, SecNonces, error) {
+	if len(secret) == 0 {
+		return PubNonces{}, SecNonces{}, errors.New("secret seed is required")
+	}
+
+	deriveScalar := func(index byte) btcec.ModNScalar {
+		var buf bytes.Buffer
+		buf.Write(secret)
+		buf.Write(schnorr.SerializePubKey(s.aggPubKey))
+		buf.WriteByte(index)
+		digest := taggedHash("MuSig/nonce", buf.Bytes())
+
+		var scalar btcec.ModNScalar
+		scalar.SetByteSlice(digest)
+		return scalar
+	}
+
+	secNonce := SecNonces{K1: deriveScalar(0x00), K2: deriveScalar(0x01)}
+
+	var r1Point, r2Point btcec.JacobianPoint
+	btcec.ScalarBaseMultNonConst(&secNonce.K1, &r1Point)
+	btcec.ScalarBaseMultNonConst(&secNonce.K2, &r2Point)
+	r1Point.ToAffine()
+	r2Point.ToAffine()
+
+	pubNonce := PubNonces{
+		R1: btcec.NewPublicKey(&r1Point.X, &r1Point.Y),
+		R2: btcec.NewPublicKey(&r2Point.X, &r2Point.Y),
+	}
+	return pubNonce, secNonce, nil
+}
+
+// AggregateNonces combines every participant's PubNonces into a single
+// AggNonce via componentwise point addition of each (R1_i, R2_i).
+func (s *Session) AggregateNonces(all []PubNonces) (AggNonce, error) {
+	if len(all) == 0 {
+		return AggNonce{}, errors.New("at least one public nonce is required")
+	}
+
+	var aggR1, aggR2 btcec.JacobianPoint
+	aggR1.X.SetInt(0)
+	aggR1.Y.SetInt(0)
+	aggR1.Z.SetInt(0)
+	aggR2.X.SetInt(0)
+	aggR2.Y.SetInt(0)
+	aggR2.Z.SetInt(0)
+
+	for _, pn := range all {
+		var r1, r2 btcec.JacobianPoint
+		pn.R1.AsJacobian(&r1)
+		pn.R2.AsJacobian(&r2)
+
+		btcec.AddNonConst(&aggR1, &r1, &aggR1)
+		btcec.AddNonConst(&aggR2, &r2, &aggR2)
+	}
+
+	aggR1.ToAffine()
+	aggR2.ToAffine()
+
+	return AggNonce{
+		R1: btcec.NewPublicKey(&aggR1.X, &aggR1.Y),
+		R2: btcec.NewPublicKey(&aggR2.X, &aggR2.Y),
+	}, nil
+}
+
+// PartialSign produces secKey's contribution to the round's aggregated
+// signature over sighash (typically SighashForTaprootKeyPath's output
+// for the input this round signs): s_i = k1_i + b*k2_i + e*a_i*x_i,
+// where x_i is secKey's secret scalar adjusted so it corresponds to the
+// even-Y public key the aggregate and this session's output key actually
+// use. It also caches the round context CombineSigs needs, so every
+// PartialSign call in a round must be given the same aggNonce and
+// sighash.
+func (s *Session) PartialSign(secKey *btcec.PrivateKey, secNonce SecNonces, aggNonce AggNonce, sighash []byte) (PartialSig, error) {
+	var zero PartialSig
+	if secKey == nil {
+		return zero, errors.New("secret key is required")
+	}
+	if len(sighash) == 0 {
+		return zero, errors.New("sighash is required")
+	}
+
+	ctx, err := s.context(aggNonce, sighash)
+	if err != nil {
+		return zero, err
+	}
+
+	coefficient, err := keyAggCoefficient(s.participants, secKey.PubKey())
+	if err != nil {
+		return zero, err
+	}
+
+	k1, k2 := secNonce.K1, secNonce.K2
+	if ctx.rNegated {
+		k1.Negate()
+		k2.Negate()
+	}
+
+	// x_i must correspond to the even-Y public key actually used in the
+	// aggregate, and be negated again if the session's output key is
+	// odd-Y. arkbuilders.MuSig2AggregateKeys sums each participant's
+	// point with whatever parity it naturally has, so the bit to check
+	// here is the pre-tweak aggregate key's parity, not secKey's own.
+	d := secKey.Key
+	var aggPubKeyPoint btcec.JacobianPoint
+	s.aggPubKey.AsJacobian(&aggPubKeyPoint)
+	aggPubKeyPoint.ToAffine()
+	if aggPubKeyPoint.Y.IsOdd() {
+		d.Negate()
+	}
+	if s.outputParity {
+		d.Negate()
+	}
+
+	var bk2 btcec.ModNScalar
+	bk2.Mul2(&ctx.b, &k2)
+
+	var eCoeff btcec.ModNScalar
+	eCoeff.Mul2(&ctx.e, coefficient)
+	eCoeff.Mul(&d)
+
+	var sig btcec.ModNScalar
+	sig.Set(&k1)
+	sig.Add(&bk2)
+	sig.Add(&eCoeff)
+
+	var out PartialSig
+	sig.PutBytesUnchecked(out[:])
+	return out, nil
+}
+
+// CombineSigs sums every participant's PartialSig into a single scalar
+// s = Σ s_i + e*g*tweak (mod n) and pairs it with the round's effective
+// nonce R to produce the final 64-byte BIP-340 Schnorr signature on the
+// session's output key. At least one PartialSign call must have run
+// first, to establish the round's effective nonce. The tweak
+// contribution is added exactly once here, per BIP-327's tweaking
+// extension, rather than by each signer in PartialSign, since every
+// signer's round context (and thus e) is identical and a per-signer term
+// would multiply it by the number of signers. g flips the tweak's sign
+// to match the even-Y convention applied to the output key.
+func (s *Session) CombineSigs(partials []PartialSig) ([]byte, error) {
+	if s.ctx == nil {
+		return nil, errors.New("no signing round in progress: call PartialSign at least once before combining")
+	}
+	if len(partials) == 0 {
+		return nil, errors.New("at least one partial signature is required")
+	}
+
+	var sum btcec.ModNScalar
+	for _, ps := range partials {
+		var si btcec.ModNScalar
+		if overflow := si.SetBytes((*[32]byte)(&ps)); overflow != 0 {
+			return nil, errors.New("partial signature is not a valid scalar")
+		}
+		sum.Add(&si)
+	}
+
+	if s.tweak != nil {
+		var eTweak btcec.ModNScalar
+		eTweak.Set(s.tweak)
+		if s.outputParity {
+			eTweak.Negate()
+		}
+		eTweak.Mul(&s.ctx.e)
+		sum.Add(&eTweak)
+	}
+
+	rXOnly := schnorr.SerializePubKey(s.ctx.r)
+	var sigBytes [64]byte
+	copy(sigBytes[:32], rXOnly)
+	sum.PutBytesUnchecked(sigBytes[32:])
+
+	if _, err := schnorr.ParseSignature(sigBytes[:]); err != nil {
+		return nil, err
+	}
+	return sigBytes[:], nil
+}
+
+// context builds the effective nonce R and BIP-340 challenge e for
+// aggNonce and sighash, and caches them on the session so CombineSigs
+// can recover R afterwards without aggNonce and sighash being passed to
+// it again. Every PartialSign call in a round must agree on aggNonce and
+// sighash; context reuses the cached value when a later call repeats the
+// same pair (compared by serialized nonce bytes, since AggNonce holds
+// pointers that differ across separate AggregateNonces calls even for
+// numerically identical nonces).
+func (s *Session) context(aggNonce AggNonce, sighash []byte) (*roundContext, error) {
+	if s.ctx != nil && sameAggNonce(s.ctx.aggNonce, aggNonce) && bytes.Equal(s.ctx.sighash, sighash) {
+		return s.ctx, nil
+	}
+
+	b, err := nonceCoefficient(aggNonce, s.outputKey, sighash)
+	if err != nil {
+		return nil, err
+	}
+
+	var r1, r2, bR2, rPoint btcec.JacobianPoint
+	aggNonce.R1.AsJacobian(&r1)
+	aggNonce.R2.AsJacobian(&r2)
+	btcec.ScalarMultNonConst(&b, &r2, &bR2)
+	btcec.AddNonConst(&r1, &bR2, &rPoint)
+	rPoint.ToAffine()
+
+	rNegated := rPoint.Y.IsOdd()
+	if rNegated {
+		rPoint.Y.Negate(1)
+		rPoint.Y.Normalize()
+	}
+	rEven := btcec.NewPublicKey(&rPoint.X, &rPoint.Y)
+
+	outputKeyXOnly := schnorr.SerializePubKey(s.outputKey)
+	var eBuf bytes.Buffer
+	eBuf.Write(schnorr.SerializePubKey(rEven))
+	eBuf.Write(outputKeyXOnly)
+	eBuf.Write(sighash)
+	var e btcec.ModNScalar
+	e.SetByteSlice(taggedHash("BIP0340/challenge", eBuf.Bytes()))
+
+	ctx := &roundContext{
+		aggNonce: aggNonce,
+		sighash:  sighash,
+		b:        b,
+		r:        rEven,
+		rNegated: rNegated,
+		e:        e,
+	}
+	s.ctx = ctx
+	return ctx, nil
+}
+
+// sameAggNonce reports whether a and b aggregate to the same nonce pair,
+// comparing serialized points rather than the *btcec.PublicKey pointers
+// AggNonce holds (two AggregateNonces calls over the same PubNonces
+// never return the same pointers).
+func sameAggNonce(a, b AggNonce) bool {
+	if a.R1 == nil || a.R2 == nil || b.R1 == nil || b.R2 == nil {
+		return false
+	}
+	return a.R1.IsEqual(b.R1) && a.R2.IsEqual(b.R2)
+}
+
+// nonceCoefficient computes b = H_noncecoef(aggnonce, Q, m), the weight
+// PartialSign and context give R2 in the effective nonce R = R1 + b*R2.
+func nonceCoefficient(aggNonce AggNonce, outputKey *btcec.PublicKey, sighash []byte) (btcec.ModNScalar, error) {
+	if aggNonce.R1 == nil || aggNonce.R2 == nil {
+		return btcec.ModNScalar{}, errors.New("aggregated nonce is required")
+	}
+
+	var buf bytes.Buffer
+	buf.Write(schnorr.SerializePubKey(aggNonce.R1))
+	buf.Write(schnorr.SerializePubKey(aggNonce.R2))
+	buf.Write(schnorr.SerializePubKey(outputKey))
+	buf.Write(sighash)
+
+	var b btcec.ModNScalar
+	b.SetByteSlice(taggedHash("MuSig/noncecoef", buf.Bytes()))
+	return b, nil
+}
+
+// keyAggCoefficient computes the BIP-327 key-aggregation coefficient
+// a_i = H(L || Pi) for signerKey within participants, mirroring the
+// parent module's muSig2KeyAggCoefficient so the two packages' key
+// aggregation never drifts apart even though neither can call the
+// other's unexported helper directly.
+func keyAggCoefficient(participants []*btcec.PublicKey, signerKey *btcec.PublicKey) (*btcec.ModNScalar, error) {
+	if len(participants) == 0 {
+		return nil, errors.New("at least one participant public key is required")
+	}
+
+	sorted := make([]*btcec.PublicKey, len(participants))
+	copy(sorted, participants)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(
+			schnorr.SerializePubKey(sorted[i]),
+			schnorr.SerializePubKey(sorted[j]),
+		) < 0
+	})
+
+	var keyListBuf bytes.Buffer
+	for _, pk := range sorted {
+		keyListBuf.Write(schnorr.SerializePubKey(pk))
+	}
+	keyListHash := sha256.Sum256(keyListBuf.Bytes())
+
+	var coefBuf bytes.Buffer
+	coefBuf.Write(keyListHash[:])
+	coefBuf.Write(schnorr.SerializePubKey(signerKey))
+	coefHash := sha256.Sum256(coefBuf.Bytes())
+
+	var coeff btcec.ModNScalar
+	coeff.SetByteSlice(coefHash[:])
+	return &coeff, nil
+}
+
+// tapTweakScalar recomputes t = taggedHash("TapTweak", xonly(internal) ||
+// merkleRoot), the same BIP-341 tweak scalar arkbuilders.
+// TaprootTweakPublicKey applies internally but doesn't expose, so
+// CombineSigs can add its contribution to the aggregated signature.
+func tapTweakScalar(internal *btcec.PublicKey, merkleRoot []byte) (*btcec.ModNScalar, error) {
+	var p btcec.JacobianPoint
+	internal.AsJacobian(&p)
+	p.ToAffine()
+
+	if p.Y.IsOdd() {
+		p.Y.Negate(1)
+		p.Y.Normalize()
+	}
+
+	xonly := schnorr.SerializePubKey(btcec.NewPublicKey(&p.X, &p.Y))
+
+	var buf bytes.Buffer
+	buf.Write(xonly)
+	buf.Write(merkleRoot)
+	tDigest := taggedHash("TapTweak", buf.Bytes())
+
+	var t btcec.ModNScalar
+	if overflow := t.SetByteSlice(tDigest); overflow {
+		return nil, errors.New("tweak hash is not a valid scalar")
+	}
+	return &t, nil
+}
+
+// taggedHash implements the BIP-340 tagged hash construction
+// SHA256(SHA256(tag) || SHA256(tag) || data).
+func taggedHash(tag string, data []byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	h.Write(data)
+
+	return h.Sum(nil)
+}
+
+// SighashForTaprootKeyPath computes the BIP-341 key-path signature hash
+// for tx's input at inputIndex, given the previous outputs for every one
+// of tx's inputs (txscript's taproot sighash covers the whole
+// transaction, so all of them are required even under
+// SIGHASH_ANYONECANPAY). Pass txscript.SigHashDefault for boarding and
+// commitment cooperative spends, or GetSighashType() (SIGHASH_ALL) for
+// forfeits. BuildForfeitPSBT assigns SIGHASH_ALL only to the forfeit's
+// connector input; its VTXO input carries SIGHASH_ALL|ANYONECANPAY.
+func SighashForTaprootKeyPath(tx *wire.MsgTx, inputIndex int, prevOuts []*wire.TxOut, hashType txscript.SigHashType) ([]byte, error) {
+	if tx == nil {
+		return nil, errors.New("transaction is required")
+	}
+	if inputIndex < 0 || inputIndex >= len(tx.TxIn) {
+		return nil, errors.New("input index out of range")
+	}
+	if len(prevOuts) != len(tx.TxIn) {
+		return nil, errors.New("prevOuts must supply exactly one entry per input")
+	}
+
+	fetcherMap := make(map[wire.OutPoint]*wire.TxOut, len(tx.TxIn))
+	for i, txIn := range tx.TxIn {
+		fetcherMap[txIn.PreviousOutPoint] = prevOuts[i]
+	}
+	prevFetcher := txscript.NewMultiPrevOutFetcher(fetcherMap)
+	sigHashes := txscript.NewTxSigHashes(tx, prevFetcher)
+
+	return txscript.CalcTaprootSignatureHash(sigHashes, hashType, tx, inputIndex, prevFetcher)
+}
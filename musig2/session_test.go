@@ -0,0 +1,126 @@
+package musig2
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+
+	arkbuilders "github.com/utexo/ark-tx-builders"
+)
+
+func testPrivKey(t *testing.T, seed byte) *btcec.PrivateKey {
+	t.Helper()
+	keyBytes := make([]byte, 32)
+	for i := range keyBytes {
+		keyBytes[i] = seed
+	}
+	priv, _ := btcec.PrivKeyFromBytes(keyBytes)
+	return priv
+}
+
+// TestSessionSignAndVerify exercises a full two-party signing round
+// end-to-end against a real key-path spend: NewSession, GenerateNonces,
+// AggregateNonces, SighashForTaprootKeyPath, PartialSign and CombineSigs,
+// verifying the combined signature against the session's output key.
+func TestSessionSignAndVerify(t *testing.T) {
+	operatorKey := testPrivKey(t, 0x30)
+	userKey := testPrivKey(t, 0x31)
+
+	operatorSession, err := NewSession([]*btcec.PublicKey{operatorKey.PubKey(), userKey.PubKey()}, nil)
+	require.NoError(t, err)
+	userSession, err := NewSession([]*btcec.PublicKey{operatorKey.PubKey(), userKey.PubKey()}, nil)
+	require.NoError(t, err)
+
+	outputKey, _ := operatorSession.OutputKey()
+	pkScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_1).
+		AddData(schnorr.SerializePubKey(outputKey)).
+		Script()
+	require.NoError(t, err)
+	prevOut := wire.NewTxOut(50000, pkScript)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: 0}})
+	tx.AddTxOut(wire.NewTxOut(49000, []byte{txscript.OP_TRUE}))
+
+	sighash, err := SighashForTaprootKeyPath(tx, 0, []*wire.TxOut{prevOut}, txscript.SigHashDefault)
+	require.NoError(t, err)
+
+	seed := func(priv *btcec.PrivateKey) []byte {
+		txHash := tx.TxHash()
+		extra := sha256.Sum256(append(priv.Serialize(), txHash.CloneBytes()...))
+		return extra[:]
+	}
+
+	operatorPubNonce, operatorSecNonce, err := operatorSession.GenerateNonces(seed(operatorKey))
+	require.NoError(t, err)
+	userPubNonce, userSecNonce, err := userSession.GenerateNonces(seed(userKey))
+	require.NoError(t, err)
+
+	allNonces := []PubNonces{operatorPubNonce, userPubNonce}
+	aggNonce, err := operatorSession.AggregateNonces(allNonces)
+	require.NoError(t, err)
+	aggNonce2, err := userSession.AggregateNonces(allNonces)
+	require.NoError(t, err)
+
+	operatorPartial, err := operatorSession.PartialSign(operatorKey, operatorSecNonce, aggNonce, sighash)
+	require.NoError(t, err)
+	userPartial, err := userSession.PartialSign(userKey, userSecNonce, aggNonce2, sighash)
+	require.NoError(t, err)
+
+	finalSig, err := operatorSession.CombineSigs([]PartialSig{operatorPartial, userPartial})
+	require.NoError(t, err)
+
+	sig, err := schnorr.ParseSignature(finalSig)
+	require.NoError(t, err)
+	require.True(t, sig.Verify(sighash, outputKey), "combined signature must verify against the session's output key")
+}
+
+// TestSessionDeterministicNoncesReplay checks that GenerateNonces is a
+// pure function of its seed, so a signer deriving the seed from the
+// unsigned transaction (as TestSessionSignAndVerify does) can always
+// reconstruct the same nonce pair to replay a signing round.
+func TestSessionDeterministicNoncesReplay(t *testing.T) {
+	key := testPrivKey(t, 0x32)
+	other := testPrivKey(t, 0x33)
+	session, err := NewSession([]*btcec.PublicKey{key.PubKey(), other.PubKey()}, nil)
+	require.NoError(t, err)
+
+	seed := []byte("fixed-seed-for-replay")
+	pub1, sec1, err := session.GenerateNonces(seed)
+	require.NoError(t, err)
+	pub2, sec2, err := session.GenerateNonces(seed)
+	require.NoError(t, err)
+
+	require.Equal(t, sec1.K1, sec2.K1)
+	require.Equal(t, sec1.K2, sec2.K2)
+	require.True(t, pub1.R1.IsEqual(pub2.R1))
+	require.True(t, pub1.R2.IsEqual(pub2.R2))
+}
+
+// TestSessionOutputKeyMatchesAggregateKeyTweak checks that NewSession's
+// output key is the same one arkbuilders.TaprootTweakPublicKey would
+// produce directly from the same aggregate, so a Session's signatures
+// verify against whatever output key the rest of the module already
+// computes for the same participants and tweak.
+func TestSessionOutputKeyMatchesAggregateKeyTweak(t *testing.T) {
+	key1 := testPrivKey(t, 0x34)
+	key2 := testPrivKey(t, 0x35)
+
+	session, err := NewSession([]*btcec.PublicKey{key1.PubKey(), key2.PubKey()}, nil)
+	require.NoError(t, err)
+
+	aggKey, err := arkbuilders.MuSig2AggregateKeys(key1.PubKey(), key2.PubKey())
+	require.NoError(t, err)
+	wantOutputKey, wantParity, err := arkbuilders.TaprootTweakPublicKey(aggKey, nil)
+	require.NoError(t, err)
+
+	gotOutputKey, gotParity := session.OutputKey()
+	require.True(t, wantOutputKey.IsEqual(gotOutputKey))
+	require.Equal(t, wantParity, gotParity)
+}
@@ -0,0 +1,104 @@
+package arkbuilders
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// This file wraps MuSig2Session's lower-level primitives (NonceGen,
+// NewMuSig2SessionContext, PartialSign, PartialSigAgg) into a
+// fixed-message, fixed-tweak signing flow: construct once with NewSession,
+// exchange PublicNonce/RegisterNonces, then Sign and CombinePartialSigs.
+
+// NewSession starts a fixed-message MuSig2 signing session for privKey,
+// cosigning with otherPubs under taprootTweak, the merkle root the
+// aggregate key is tweaked with (nil applies the BIP-341 key-path-only
+// tweak with an empty merkle root). Unlike NewMuSig2Session, it also generates this signer's
+// own nonce deterministically from msg, so PublicNonce is ready to
+// broadcast immediately.
+func NewSession(privKey *btcec.PrivateKey, otherPubs []*btcec.PublicKey, msg [32]byte, taprootTweak []byte) (*MuSig2Session, error) {
+	session, err := NewMuSig2Session(privKey, otherPubs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.NonceGenDeterministic(msg[:], nil); err != nil {
+		return nil, err
+	}
+
+	session.msg = msg[:]
+	session.tweak = taprootTweak
+	return session, nil
+}
+
+// PublicNonce serializes this session's own public nonce as the 66-byte
+// (R1 || R2) wire format, ready to broadcast to the other cosigners.
+func (s *MuSig2Session) PublicNonce() ([66]byte, error) {
+	var out [66]byte
+	if s.pubNonce == nil {
+		return out, errors.New("nonce has not been generated yet")
+	}
+	copy(out[:33], s.pubNonce.R1.SerializeCompressed())
+	copy(out[33:], s.pubNonce.R2.SerializeCompressed())
+	return out, nil
+}
+
+// RegisterNonces aggregates every cosigner's 66-byte public nonce
+// (including this session's own, from PublicNonce) and builds the
+// session context Sign and CombinePartialSigs use.
+func (s *MuSig2Session) RegisterNonces(nonces [][66]byte) error {
+	if s.msg == nil {
+		return errors.New("session was not started via NewSession")
+	}
+	if len(nonces) == 0 {
+		return errors.New("at least one public nonce is required")
+	}
+
+	pubNonces := make([]*MuSig2PubNonce, len(nonces))
+	for i, raw := range nonces {
+		r1, err := btcec.ParsePubKey(raw[:33])
+		if err != nil {
+			return err
+		}
+		r2, err := btcec.ParsePubKey(raw[33:])
+		if err != nil {
+			return err
+		}
+		pubNonces[i] = &MuSig2PubNonce{R1: r1, R2: r2}
+	}
+
+	aggNonce, err := NonceAgg(pubNonces)
+	if err != nil {
+		return err
+	}
+
+	ctx, err := NewMuSig2SessionContext(s.aggPubKey, s.tweak, s.msg, aggNonce)
+	if err != nil {
+		return err
+	}
+	s.ctx = ctx
+	return nil
+}
+
+// Sign produces this signer's partial signature. RegisterNonces must be
+// called first to build the session context.
+func (s *MuSig2Session) Sign() ([32]byte, error) {
+	var zero [32]byte
+	if s.ctx == nil {
+		return zero, errors.New("nonces must be registered before signing")
+	}
+	return s.PartialSign(s.ctx)
+}
+
+// CombinePartialSigs aggregates every cosigner's partial signature into
+// the final BIP-340 Schnorr signature on the tweaked aggregate key.
+// RegisterNonces must be called first to build the session context the
+// aggregation needs.
+func (s *MuSig2Session) CombinePartialSigs(sigs [][32]byte) (*schnorr.Signature, error) {
+	if s.ctx == nil {
+		return nil, errors.New("nonces must be registered before combining signatures")
+	}
+	return PartialSigAgg(s.ctx, sigs)
+}
@@ -0,0 +1,60 @@
+package arkbuilders
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMuSig2ConvenienceSessionSignsForfeitOutput exercises the NewSession
+// convenience API end-to-end between two parties (standing in for the
+// operator and a VTXO user) cooperatively signing a message derived from
+// a BuildForfeitTx txid, and verifies the aggregated signature against
+// the MuSig2(operator, user) aggregate key with the BIP-341
+// key-path-only tweak applied.
+func TestMuSig2ConvenienceSessionSignsForfeitOutput(t *testing.T) {
+	builder := NewTxBuilder()
+	operatorPrivKey := createTestPrivKey(t, 0x20)
+	userPrivKey := createTestPrivKey(t, 0x21)
+
+	forfeitTx, err := builder.BuildForfeitTx(&ForfeitTxParams{
+		VTXO:            createTestUTXO(50000, 0),
+		ConnectorAnchor: createTestUTXO(1000, 1),
+		OperatorPubKey:  operatorPrivKey.PubKey(),
+		FeeRate:         1,
+	})
+	require.NoError(t, err)
+
+	txHash := forfeitTx.TxHash()
+	msg := sha256.Sum256(txHash.CloneBytes())
+
+	operatorSession, err := NewSession(operatorPrivKey, []*btcec.PublicKey{userPrivKey.PubKey()}, msg, nil)
+	require.NoError(t, err)
+	userSession, err := NewSession(userPrivKey, []*btcec.PublicKey{operatorPrivKey.PubKey()}, msg, nil)
+	require.NoError(t, err)
+
+	operatorNonce, err := operatorSession.PublicNonce()
+	require.NoError(t, err)
+	userNonce, err := userSession.PublicNonce()
+	require.NoError(t, err)
+
+	require.NoError(t, operatorSession.RegisterNonces([][66]byte{operatorNonce, userNonce}))
+	require.NoError(t, userSession.RegisterNonces([][66]byte{operatorNonce, userNonce}))
+
+	operatorPartialSig, err := operatorSession.Sign()
+	require.NoError(t, err)
+	userPartialSig, err := userSession.Sign()
+	require.NoError(t, err)
+
+	finalSig, err := operatorSession.CombinePartialSigs([][32]byte{operatorPartialSig, userPartialSig})
+	require.NoError(t, err)
+
+	aggKey, err := MuSig2AggregateKeys(operatorPrivKey.PubKey(), userPrivKey.PubKey())
+	require.NoError(t, err)
+	outputKey, _, err := TaprootTweakPublicKey(aggKey, nil)
+	require.NoError(t, err)
+
+	require.True(t, finalSig.Verify(msg[:], outputKey), "aggregated signature must verify against the taproot output key")
+}
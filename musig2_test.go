@@ -0,0 +1,57 @@
+package arkbuilders
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMuSig2SessionSignAndVerify exercises a full two-party BIP-327 style
+// signing session end-to-end: key aggregation, nonce generation/aggregation,
+// partial signing, partial verification, and aggregation into a final
+// BIP-340 signature that verifies against the session context's output
+// key (the aggregate key with the BIP-341 key-path-only tweak applied).
+func TestMuSig2SessionSignAndVerify(t *testing.T) {
+	key1 := createTestPrivKey(t, 0x10)
+	key2 := createTestPrivKey(t, 0x11)
+
+	session1, err := NewMuSig2Session(key1, key2.PubKey())
+	require.NoError(t, err)
+	session2, err := NewMuSig2Session(key2, key1.PubKey())
+	require.NoError(t, err)
+
+	msgHash := sha256.Sum256([]byte("forfeit tx sighash placeholder"))
+	msg := msgHash[:]
+
+	pubNonce1, err := session1.NonceGenDeterministic(msg, []byte("signer-1"))
+	require.NoError(t, err)
+	pubNonce2, err := session2.NonceGenDeterministic(msg, []byte("signer-2"))
+	require.NoError(t, err)
+
+	aggNonce, err := NonceAgg([]*MuSig2PubNonce{pubNonce1, pubNonce2})
+	require.NoError(t, err)
+
+	ctx, err := NewMuSig2SessionContext(session1.AggregatePubKey(), nil, msg, aggNonce)
+	require.NoError(t, err)
+
+	sig1, err := session1.PartialSign(ctx)
+	require.NoError(t, err)
+	sig2, err := session2.PartialSign(ctx)
+	require.NoError(t, err)
+
+	coeff1 := session1.Coefficient()
+	ok, err := PartialSigVerify(ctx, pubNonce1, key1.PubKey(), &coeff1, sig1)
+	require.NoError(t, err)
+	require.True(t, ok, "signer 1 partial signature must verify")
+
+	coeff2 := session2.Coefficient()
+	ok, err = PartialSigVerify(ctx, pubNonce2, key2.PubKey(), &coeff2, sig2)
+	require.NoError(t, err)
+	require.True(t, ok, "signer 2 partial signature must verify")
+
+	finalSig, err := PartialSigAgg(ctx, [][32]byte{sig1, sig2})
+	require.NoError(t, err)
+
+	require.True(t, finalSig.Verify(msg, ctx.OutputKey), "aggregated signature must verify against the output key")
+}
@@ -0,0 +1,164 @@
+package arkbuilders
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// This file provides an idiomatic functional-options API around
+// BuildBoardingTx, BuildCommitmentTx, and BuildForfeitTx. The underlying
+// *TxParams structs remain fully supported for direct use; NewBoardingTx,
+// NewCommitmentTx, and NewForfeitTx are thin option-applying wrappers
+// around them and are the preferred entry point for new callers.
+
+// BoardingTxOption configures a BoardingTxParams built by NewBoardingTx.
+type BoardingTxOption func(*BoardingTxParams)
+
+// WithTimeout sets the relative timelock (in blocks) for the boarding
+// output's user-only timeout path.
+func WithTimeout(blocks uint16) BoardingTxOption {
+	return func(p *BoardingTxParams) { p.TimeoutBlocks = blocks }
+}
+
+// WithFeeRate sets the fee rate in satoshis per vbyte.
+func WithFeeRate(feeRate int64) BoardingTxOption {
+	return func(p *BoardingTxParams) { p.FeeRate = feeRate }
+}
+
+// WithChangeAddress sets the address change above the dust limit is sent to.
+func WithChangeAddress(address string) BoardingTxOption {
+	return func(p *BoardingTxParams) { p.ChangeAddress = address }
+}
+
+// WithMemo attaches up to MaxMemoSize bytes of arbitrary application data
+// as a trailing OP_RETURN output, positioned last so it never disturbs
+// the sorted batch/connector/change outputs.
+func WithMemo(memo []byte) BoardingTxOption {
+	return func(p *BoardingTxParams) { p.Memo = memo }
+}
+
+// NewBoardingTx builds a boarding transaction from positional required
+// parameters plus BoardingTxOptions for everything optional.
+func (tb *TxBuilder) NewBoardingTx(fundingUTXO *UTXO, amount int64, userPubKey, operatorPubKey *btcec.PublicKey, opts ...BoardingTxOption) (*wire.MsgTx, error) {
+	params := &BoardingTxParams{
+		FundingUTXO:    fundingUTXO,
+		Amount:         amount,
+		UserPubKey:     userPubKey,
+		OperatorPubKey: operatorPubKey,
+	}
+	for _, opt := range opts {
+		opt(params)
+	}
+	return tb.BuildBoardingTx(params)
+}
+
+// CommitmentTxOption configures a CommitmentTxParams built by NewCommitmentTx.
+type CommitmentTxOption func(*CommitmentTxParams)
+
+// WithBatchExpiry sets the absolute locktime for the batch output's
+// operator sweep path.
+func WithBatchExpiry(expiry uint32) CommitmentTxOption {
+	return func(p *CommitmentTxParams) { p.BatchExpiry = expiry }
+}
+
+// WithConnectorAmount sets the connector output's amount (clamped to
+// DustLimit by BuildCommitmentTx if lower).
+func WithConnectorAmount(amount int64) CommitmentTxOption {
+	return func(p *CommitmentTxParams) { p.ConnectorAmount = amount }
+}
+
+// WithExtraOperatorUTXOs appends additional operator-controlled UTXOs to
+// the ones passed to NewCommitmentTx. BuildCommitmentTx selects its
+// operator inputs from all of them (see OperatorUTXOStrategy), so an
+// extra UTXO only becomes an input if coin selection picks it.
+func WithExtraOperatorUTXOs(utxos ...*UTXO) CommitmentTxOption {
+	return func(p *CommitmentTxParams) { p.OperatorUTXOs = append(p.OperatorUTXOs, utxos...) }
+}
+
+// WithCommitmentFeeRate sets the fee rate in satoshis per vbyte.
+func WithCommitmentFeeRate(feeRate int64) CommitmentTxOption {
+	return func(p *CommitmentTxParams) { p.FeeRate = feeRate }
+}
+
+// WithCommitmentMemo attaches up to MaxMemoSize bytes of arbitrary
+// application data as a trailing OP_RETURN output.
+func WithCommitmentMemo(memo []byte) CommitmentTxOption {
+	return func(p *CommitmentTxParams) { p.Memo = memo }
+}
+
+// WithCommitmentEphemeralAnchor opts the commitment tx into TRUC (v3)
+// with a trailing zero-value ephemeral anchor, so a third party's CPFP
+// child can bump its fee via BuildAnchorSpend. The commitment tx's own
+// fee is still sized at its full FeeRate.
+func WithCommitmentEphemeralAnchor() CommitmentTxOption {
+	return func(p *CommitmentTxParams) { p.UseEphemeralAnchor = true }
+}
+
+// WithCommitmentKeyedAnchor opts the commitment tx into a trailing
+// AnchorKeyed output paying bumpKey instead of an ephemeral P2A one, for
+// callers that want the CPFP handle restricted to a specific key.
+func WithCommitmentKeyedAnchor(bumpKey *btcec.PublicKey) CommitmentTxOption {
+	return func(p *CommitmentTxParams) {
+		p.AnchorPolicy = AnchorKeyed
+		p.BumpKey = bumpKey
+	}
+}
+
+// NewCommitmentTx builds a commitment transaction from positional
+// required parameters plus CommitmentTxOptions for everything optional.
+func (tb *TxBuilder) NewCommitmentTx(operatorUTXOs []*UTXO, batchAmount int64, operatorPubKey *btcec.PublicKey, opts ...CommitmentTxOption) (*wire.MsgTx, error) {
+	params := &CommitmentTxParams{
+		OperatorUTXOs:  operatorUTXOs,
+		BatchAmount:    batchAmount,
+		OperatorPubKey: operatorPubKey,
+	}
+	for _, opt := range opts {
+		opt(params)
+	}
+	return tb.BuildCommitmentTx(params)
+}
+
+// ForfeitTxOption configures a ForfeitTxParams built by NewForfeitTx.
+type ForfeitTxOption func(*ForfeitTxParams)
+
+// WithForfeitFeeRate sets the fee rate in satoshis per vbyte.
+func WithForfeitFeeRate(feeRate int64) ForfeitTxOption {
+	return func(p *ForfeitTxParams) { p.FeeRate = feeRate }
+}
+
+// WithForfeitMemo attaches up to MaxMemoSize bytes of arbitrary
+// application data as a trailing OP_RETURN output.
+func WithForfeitMemo(memo []byte) ForfeitTxOption {
+	return func(p *ForfeitTxParams) { p.Memo = memo }
+}
+
+// WithForfeitEphemeralAnchor opts the forfeit tx into TRUC (v3) with a
+// trailing zero-value ephemeral anchor; see
+// WithCommitmentEphemeralAnchor for the rationale.
+func WithForfeitEphemeralAnchor() ForfeitTxOption {
+	return func(p *ForfeitTxParams) { p.UseEphemeralAnchor = true }
+}
+
+// WithForfeitKeyedAnchor opts the forfeit tx into a trailing AnchorKeyed
+// output paying bumpKey instead of an ephemeral P2A one; see
+// WithCommitmentKeyedAnchor for the rationale.
+func WithForfeitKeyedAnchor(bumpKey *btcec.PublicKey) ForfeitTxOption {
+	return func(p *ForfeitTxParams) {
+		p.AnchorPolicy = AnchorKeyed
+		p.BumpKey = bumpKey
+	}
+}
+
+// NewForfeitTx builds a forfeit transaction from positional required
+// parameters plus ForfeitTxOptions for everything optional.
+func (tb *TxBuilder) NewForfeitTx(vtxo, connectorAnchor *UTXO, operatorPubKey *btcec.PublicKey, opts ...ForfeitTxOption) (*wire.MsgTx, error) {
+	params := &ForfeitTxParams{
+		VTXO:            vtxo,
+		ConnectorAnchor: connectorAnchor,
+		OperatorPubKey:  operatorPubKey,
+	}
+	for _, opt := range opts {
+		opt(params)
+	}
+	return tb.BuildForfeitTx(params)
+}
@@ -0,0 +1,51 @@
+package arkbuilders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewBoardingTxWithMemo verifies the functional-options constructor
+// builds a boarding transaction with the memo attached as the final,
+// zero-value OP_RETURN output.
+func TestNewBoardingTxWithMemo(t *testing.T) {
+	builder := NewTxBuilder()
+	userPrivKey := createTestPrivKey(t, 0x01)
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+
+	tx, err := builder.NewBoardingTx(
+		createTestUTXO(100000, 0),
+		90000,
+		userPrivKey.PubKey(),
+		operatorPrivKey.PubKey(),
+		WithTimeout(144),
+		WithFeeRate(1),
+		WithMemo([]byte("ark-boarding-v1")),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, tx)
+
+	last := tx.TxOut[len(tx.TxOut)-1]
+	require.Equal(t, int64(0), last.Value)
+	require.Equal(t, byte(0x6a), last.PkScript[0]) // OP_RETURN
+}
+
+// TestMemoExceedsMaxSize verifies the memo output helper rejects
+// oversized payloads instead of silently truncating them.
+func TestMemoExceedsMaxSize(t *testing.T) {
+	builder := NewTxBuilder()
+	userPrivKey := createTestPrivKey(t, 0x01)
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+
+	oversized := make([]byte, MaxMemoSize+1)
+
+	_, err := builder.NewBoardingTx(
+		createTestUTXO(100000, 0),
+		90000,
+		userPrivKey.PubKey(),
+		operatorPrivKey.PubKey(),
+		WithMemo(oversized),
+	)
+	require.Error(t, err)
+}
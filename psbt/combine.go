@@ -0,0 +1,28 @@
+package psbt
+
+import (
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/wire"
+
+	arkbuilders "github.com/utexo/ark-tx-builders"
+)
+
+// CombinePSBT merges independently-signed copies of the same unsigned
+// PSBT into one, via arkbuilders.Combine: MuSig2 partial signatures and
+// other proprietary Unknowns accumulate per input, and any Taproot
+// script-path signature attached for a leaf (whether the cooperative
+// MuSig2-aggregated leaf or the plain timeout leaf) is carried over
+// untouched, so each cosigner can keep signing their own copy and hand
+// it back here for aggregation.
+func CombinePSBT(psbts ...*psbt.Packet) (*psbt.Packet, error) {
+	return arkbuilders.Combine(psbts...)
+}
+
+// FinalizePSBT assembles the fully witness-populated transaction a
+// combined PSBT describes, via arkbuilders.Finalize: each input gets a
+// key-path witness if one is present, otherwise a script-path witness
+// from whichever leaf (cooperative or timeout) has a complete signature,
+// preferring the smallest such witness when more than one leaf qualifies.
+func FinalizePSBT(packet *psbt.Packet) (*wire.MsgTx, error) {
+	return arkbuilders.Finalize(packet)
+}
@@ -0,0 +1,61 @@
+package psbt
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/require"
+
+	arkbuilders "github.com/utexo/ark-tx-builders"
+)
+
+func testUTXO(amount int64, index uint32) *arkbuilders.UTXO {
+	hash, _ := chainhash.NewHashFromStr("0000000000000000000000000000000000000000000000000000000000000001")
+	return &arkbuilders.UTXO{
+		TxHash:      *hash,
+		OutputIndex: index,
+		Amount:      amount,
+	}
+}
+
+func testPrivKey(seed byte) *btcec.PrivateKey {
+	keyBytes := make([]byte, 32)
+	for i := range keyBytes {
+		keyBytes[i] = seed
+	}
+	priv, _ := btcec.PrivKeyFromBytes(keyBytes)
+	return priv
+}
+
+// TestCombineFinalizePSBTMatchesDirectBuild verifies that CombinePSBT
+// (trivially, over a single signed copy) followed by FinalizePSBT
+// reproduces the same txid BuildBoardingTx would for identical params.
+func TestCombineFinalizePSBTMatchesDirectBuild(t *testing.T) {
+	builder := arkbuilders.NewTxBuilder()
+	userPrivKey := testPrivKey(0x01)
+	operatorPrivKey := testPrivKey(0x02)
+
+	params := &arkbuilders.BoardingTxParams{
+		FundingUTXO:    testUTXO(100000, 0),
+		Amount:         90000,
+		UserPubKey:     userPrivKey.PubKey(),
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		TimeoutBlocks:  144,
+		FeeRate:        1,
+	}
+
+	directTx, err := builder.BuildBoardingTx(params)
+	require.NoError(t, err)
+
+	packet, err := builder.BuildBoardingPSBT(params)
+	require.NoError(t, err)
+	packet.Inputs[0].TaprootKeySpendSig = make([]byte, 64)
+
+	combined, err := CombinePSBT(packet)
+	require.NoError(t, err)
+
+	finalTx, err := FinalizePSBT(combined)
+	require.NoError(t, err)
+	require.Equal(t, directTx.TxHash(), finalTx.TxHash())
+}
@@ -0,0 +1,101 @@
+// Package psbt bridges this module's Taproot artifacts (TaprootSpendInfo,
+// script-path witnesses) to BIP-174/BIP-371 PSBTs, so builder output can be
+// handed to external signers such as hardware wallets and watchtowers.
+package psbt
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	arkbuilders "github.com/utexo/ark-tx-builders"
+)
+
+// AttachTaprootSpendInfo populates packet.Inputs[inputIndex]'s BIP-371
+// fields from a TaprootSpendInfo: PSBT_IN_TAP_INTERNAL_KEY (0x17),
+// PSBT_IN_TAP_MERKLE_ROOT (0x18), and one PSBT_IN_TAP_LEAF_SCRIPT (0x15)
+// entry per leaf script, keyed by that leaf's control block.
+func AttachTaprootSpendInfo(packet *psbt.Packet, inputIndex int, info *arkbuilders.TaprootSpendInfo, leafScripts [][]byte) error {
+	if packet == nil {
+		return errors.New("psbt packet is required")
+	}
+	if inputIndex < 0 || inputIndex >= len(packet.Inputs) {
+		return errors.New("input index out of range")
+	}
+	if info == nil {
+		return errors.New("taproot spend info is required")
+	}
+
+	internalKey, _ := info.TweakedInternalKey()
+	input := &packet.Inputs[inputIndex]
+	input.TaprootInternalKey = schnorr.SerializePubKey(internalKey)
+	input.TaprootMerkleRoot = info.MerkleRoot()
+
+	for _, script := range leafScripts {
+		controlBlock, err := info.ControlBlock(script)
+		if err != nil {
+			return err
+		}
+
+		input.TaprootLeafScript = append(input.TaprootLeafScript, &psbt.TaprootTapLeafScript{
+			ControlBlock: controlBlock,
+			Script:       script,
+			LeafVersion:  txscript.BaseLeafVersion,
+		})
+	}
+
+	return nil
+}
+
+// ExtractTaprootWitness finalizes packet.Inputs[inputIndex] into a
+// witness stack: a key-path witness from PSBT_IN_TAP_KEY_SIG if present,
+// otherwise a script-path witness assembled from the highest-priority
+// leaf (the first one, by AttachTaprootSpendInfo's insertion order) that
+// has a complete PSBT_IN_TAP_SCRIPT_SIG set.
+func ExtractTaprootWitness(packet *psbt.Packet, inputIndex int) (wire.TxWitness, error) {
+	if packet == nil {
+		return nil, errors.New("psbt packet is required")
+	}
+	if inputIndex < 0 || inputIndex >= len(packet.Inputs) {
+		return nil, errors.New("input index out of range")
+	}
+
+	input := packet.Inputs[inputIndex]
+
+	if len(input.TaprootKeySpendSig) > 0 {
+		return wire.TxWitness{input.TaprootKeySpendSig}, nil
+	}
+
+	for _, leaf := range input.TaprootLeafScript {
+		leafHash := arkbuilders.TapLeafHash(leaf.Script)
+
+		sig := findTaprootScriptSig(input.TaprootScriptSpendSig, leafHash)
+		if sig == nil {
+			continue
+		}
+
+		return arkbuilders.AssembleTaprootScriptWitness(
+			[][]byte{sig.Signature},
+			leaf.Script,
+			leaf.ControlBlock,
+		), nil
+	}
+
+	return nil, errors.New("no complete taproot key-path or script-path signature found for this input")
+}
+
+// findTaprootScriptSig returns the first script-spend signature bound to
+// leafHash, or nil if none of the input's recorded partial signatures
+// cover that leaf.
+func findTaprootScriptSig(sigs []*psbt.TaprootScriptSpendSig, leafHash []byte) *psbt.TaprootScriptSpendSig {
+	for _, sig := range sigs {
+		if bytes.Equal(sig.LeafHash, leafHash) {
+			return sig
+		}
+	}
+	return nil
+}
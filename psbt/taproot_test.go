@@ -0,0 +1,57 @@
+package psbt
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+
+	arkbuilders "github.com/utexo/ark-tx-builders"
+)
+
+// TestAttachAndExtractTaprootScriptPath verifies that a leaf script
+// attached via AttachTaprootSpendInfo can be recovered and finalized
+// into a witness once a script-spend signature is recorded for it.
+func TestAttachAndExtractTaprootScriptPath(t *testing.T) {
+	keyBytes := make([]byte, 32)
+	for i := range keyBytes {
+		keyBytes[i] = 0x09
+	}
+	priv, _ := btcec.PrivKeyFromBytes(keyBytes)
+	pub := priv.PubKey()
+
+	script, err := arkbuilders.BuildCheckSigScript(pub)
+	require.NoError(t, err)
+
+	info, err := arkbuilders.NewTaprootSpendInfo(pub, [][]byte{script})
+	require.NoError(t, err)
+
+	hash, err := chainhash.NewHashFromStr("0000000000000000000000000000000000000000000000000000000000000001")
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(90000, info.ScriptPubKey()))
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	require.NoError(t, err)
+
+	err = AttachTaprootSpendInfo(packet, 0, info, [][]byte{script})
+	require.NoError(t, err)
+	require.Len(t, packet.Inputs[0].TaprootLeafScript, 1)
+
+	leafHash := arkbuilders.TapLeafHash(script)
+	packet.Inputs[0].TaprootScriptSpendSig = append(packet.Inputs[0].TaprootScriptSpendSig, &psbt.TaprootScriptSpendSig{
+		XOnlyPubKey: packet.Inputs[0].TaprootLeafScript[0].ControlBlock[1:33],
+		LeafHash:    leafHash,
+		Signature:   []byte("64-byte-schnorr-sig-placeholder-value-padded-out-to-len---64!!"),
+	})
+
+	witness, err := ExtractTaprootWitness(packet, 0)
+	require.NoError(t, err)
+	require.Len(t, witness, 3)
+	require.Equal(t, script, []byte(witness[1]))
+}
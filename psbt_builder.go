@@ -0,0 +1,424 @@
+package arkbuilders
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// This file bridges the deterministic builders above to BIP-174/BIP-371
+// PSBTs, so a partially-signed Ark transaction can round-trip through
+// standard wallets and cosigners before being finalized. Each
+// BuildXxxPSBT delegates to the matching BuildXxxTx for the actual
+// transaction shape (inputs, outputs, amounts, ordering) and only adds
+// PSBT metadata on top, so a PSBT's Finalize always yields the same
+// txid BuildXxxTx would.
+
+// psbtMuSig2Identifier is the BIP-174 proprietary identifier used for
+// this package's MuSig2 key-value fields, since btcutil/psbt has no
+// native MuSig2 support.
+const psbtMuSig2Identifier = "ArkMuSig2"
+
+const (
+	psbtMuSig2ParticipantPubKeys byte = 0x01
+	psbtMuSig2PubNonce           byte = 0x02
+	psbtMuSig2PartialSig         byte = 0x03
+)
+
+// muSig2ProprietaryKey builds a BIP-174 proprietary key (keytype 0xFC)
+// for subtype under psbtMuSig2Identifier, with subKeyData (typically a
+// participant's serialized pubkey) appended so per-participant fields on
+// the same input don't collide.
+func muSig2ProprietaryKey(subtype byte, subKeyData []byte) []byte {
+	key := []byte{0xFC, byte(len(psbtMuSig2Identifier))}
+	key = append(key, psbtMuSig2Identifier...)
+	key = append(key, subtype)
+	key = append(key, subKeyData...)
+	return key
+}
+
+// attachMuSig2ParticipantPubKeys records the full cosigner set for a
+// 2-of-2 (or larger) MuSig2 key-path spend, as PSBT_IN_MUSIG2_PARTICIPANT_PUBKEYS.
+func attachMuSig2ParticipantPubKeys(input *psbt.PInput, pubKeys ...*btcec.PublicKey) {
+	var value bytes.Buffer
+	for _, pk := range pubKeys {
+		value.Write(schnorr.SerializePubKey(pk))
+	}
+	input.Unknowns = append(input.Unknowns, &psbt.Unknown{
+		Key:   muSig2ProprietaryKey(psbtMuSig2ParticipantPubKeys, nil),
+		Value: value.Bytes(),
+	})
+}
+
+// AttachMuSig2PubNonce records participantPubKey's public nonce for this
+// input's MuSig2 session, as PSBT_IN_MUSIG2_PUB_NONCE. Keyed by the
+// participant's pubkey so multiple cosigners can each attach their own
+// nonce without overwriting one another.
+func AttachMuSig2PubNonce(input *psbt.PInput, participantPubKey *btcec.PublicKey, nonce *MuSig2PubNonce) {
+	value := append(nonce.R1.SerializeCompressed(), nonce.R2.SerializeCompressed()...)
+	input.Unknowns = append(input.Unknowns, &psbt.Unknown{
+		Key:   muSig2ProprietaryKey(psbtMuSig2PubNonce, schnorr.SerializePubKey(participantPubKey)),
+		Value: value,
+	})
+}
+
+// AttachMuSig2PartialSig records participantPubKey's partial signature
+// for this input's MuSig2 session, as PSBT_IN_MUSIG2_PARTIAL_SIG.
+func AttachMuSig2PartialSig(input *psbt.PInput, participantPubKey *btcec.PublicKey, partialSig [32]byte) {
+	input.Unknowns = append(input.Unknowns, &psbt.Unknown{
+		Key:   muSig2ProprietaryKey(psbtMuSig2PartialSig, schnorr.SerializePubKey(participantPubKey)),
+		Value: partialSig[:],
+	})
+}
+
+// psbtArkIdentifier is the BIP-174 proprietary identifier this package
+// uses for Ark-specific metadata that stock PSBT signers have no notion
+// of (a boarding output's timeout, a batch's expiry, a connector's output
+// index) but which a cosigner or watchtower may still want to recover
+// from the packet alone.
+const psbtArkIdentifier = "ark"
+
+const (
+	psbtArkTimeoutBlocks  byte = 0x01
+	psbtArkBatchExpiry    byte = 0x02
+	psbtArkConnectorIndex byte = 0x03
+)
+
+// arkProprietaryKey builds a BIP-174 proprietary key (keytype 0xFC) for
+// subtype under psbtArkIdentifier.
+func arkProprietaryKey(subtype byte) []byte {
+	key := []byte{0xFC, byte(len(psbtArkIdentifier))}
+	key = append(key, psbtArkIdentifier...)
+	key = append(key, subtype)
+	return key
+}
+
+// attachWitnessUtxo records utxo's amount and script as the BIP-371
+// witness UTXO for input i, the minimum a Taproot-aware signer needs to
+// compute a sighash even before any script-path fields are known.
+func attachWitnessUtxo(packet *psbt.Packet, i int, utxo *UTXO) {
+	packet.Inputs[i].WitnessUtxo = wire.NewTxOut(utxo.Amount, utxo.ScriptPubKey)
+}
+
+// attachTaprootLeaf populates an input's BIP-371 internal-key, merkle
+// root, and control block for a single leaf script, given that leaf's
+// TaprootSpendInfo.
+func attachTaprootLeaf(input *psbt.PInput, info *TaprootSpendInfo, script []byte) error {
+	internalKey, _ := info.TweakedInternalKey()
+	input.TaprootInternalKey = schnorr.SerializePubKey(internalKey)
+	input.TaprootMerkleRoot = info.MerkleRoot()
+	input.TaprootBip32Derivation = append(input.TaprootBip32Derivation, &psbt.TaprootBip32Derivation{
+		XOnlyPubKey: input.TaprootInternalKey,
+	})
+
+	controlBlock, err := info.ControlBlock(script)
+	if err != nil {
+		return err
+	}
+	input.TaprootLeafScript = append(input.TaprootLeafScript, &psbt.TaprootTapLeafScript{
+		ControlBlock: controlBlock,
+		Script:       script,
+		LeafVersion:  txscript.BaseLeafVersion,
+	})
+	return nil
+}
+
+// BuildBoardingPSBT builds the boarding transaction BuildBoardingTx would
+// and wraps it in a PSBT. Input 0's Taproot internal key hints at the
+// user's own key-path coin, and input 0 carries the timeout-blocks
+// field; it also carries a witness UTXO when the transaction is funded
+// from a single FundingUTXO. When it is funded from FundingUTXOs, no
+// input carries a witness UTXO and inputs after 0 carry no hints. The
+// boarding output itself (the
+// transaction's real cooperative/timeout tree) isn't known until a later
+// transaction spends it, so its fields belong on that transaction's PSBT
+// instead.
+func (tb *TxBuilder) BuildBoardingPSBT(params *BoardingTxParams) (*psbt.Packet, error) {
+	tx, err := tb.BuildBoardingTx(params)
+	if err != nil {
+		return nil, err
+	}
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	userXOnly := schnorr.SerializePubKey(params.UserPubKey)
+	packet.Inputs[0].TaprootInternalKey = userXOnly
+	packet.Inputs[0].TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{
+		{XOnlyPubKey: userXOnly},
+	}
+	packet.Inputs[0].SighashType = txscript.SigHashDefault
+	if params.FundingUTXO != nil {
+		attachWitnessUtxo(packet, 0, params.FundingUTXO)
+	}
+
+	var timeoutValue [2]byte
+	binary.BigEndian.PutUint16(timeoutValue[:], params.TimeoutBlocks)
+	packet.Inputs[0].Unknowns = append(packet.Inputs[0].Unknowns, &psbt.Unknown{
+		Key:   arkProprietaryKey(psbtArkTimeoutBlocks),
+		Value: timeoutValue[:],
+	})
+
+	return packet, nil
+}
+
+// BuildCommitmentPSBT builds the commitment transaction BuildCommitmentTx
+// would and wraps it in a PSBT. Every input is hinted as a key-path-only
+// coin controlled by OperatorPubKey (operator UTXOs are operator coins
+// directly; boarding outputs have an unspendable key path, and their
+// MuSig2(user, operator) and user-timeout leaves are not attached here,
+// so the operator key on them is only a hint); the batch output's cooperative unroll path is the
+// one place multiple cosigners genuinely participate, so its MuSig2
+// participant set is recorded there.
+func (tb *TxBuilder) BuildCommitmentPSBT(params *CommitmentTxParams) (*psbt.Packet, error) {
+	tx, err := tb.BuildCommitmentTx(params)
+	if err != nil {
+		return nil, err
+	}
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// BuildCommitmentTx sorts inputs deterministically, so recover which
+	// original UTXO each final input spends by its outpoint rather than
+	// assuming OperatorUTXOs/BoardingOutputs' own order survived.
+	utxoByOutpoint := make(map[wire.OutPoint]*UTXO)
+	for _, utxo := range params.OperatorUTXOs {
+		utxoByOutpoint[*wire.NewOutPoint(&utxo.TxHash, utxo.OutputIndex)] = utxo
+	}
+	for _, utxo := range params.BoardingOutputs {
+		utxoByOutpoint[*wire.NewOutPoint(&utxo.TxHash, utxo.OutputIndex)] = utxo
+	}
+
+	operatorXOnly := schnorr.SerializePubKey(params.OperatorPubKey)
+	for i := range packet.Inputs {
+		packet.Inputs[i].TaprootInternalKey = operatorXOnly
+		packet.Inputs[i].TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{
+			{XOnlyPubKey: operatorXOnly},
+		}
+		packet.Inputs[i].SighashType = txscript.SigHashDefault
+
+		if utxo, ok := utxoByOutpoint[tx.TxIn[i].PreviousOutPoint]; ok {
+			attachWitnessUtxo(packet, i, utxo)
+		}
+	}
+
+	if len(params.UserPubKeys) > 0 {
+		var value bytes.Buffer
+		for _, pk := range params.UserPubKeys {
+			value.Write(schnorr.SerializePubKey(pk))
+		}
+		packet.Outputs[0].Unknowns = append(packet.Outputs[0].Unknowns, &psbt.Unknown{
+			Key:   muSig2ProprietaryKey(psbtMuSig2ParticipantPubKeys, nil),
+			Value: value.Bytes(),
+		})
+	}
+
+	var batchExpiryValue [4]byte
+	binary.BigEndian.PutUint32(batchExpiryValue[:], params.BatchExpiry)
+	packet.Outputs[0].Unknowns = append(packet.Outputs[0].Unknowns, &psbt.Unknown{
+		Key:   arkProprietaryKey(psbtArkBatchExpiry),
+		Value: batchExpiryValue[:],
+	})
+
+	return packet, nil
+}
+
+// BuildForfeitPSBT builds the forfeit transaction BuildForfeitTx would
+// and wraps it in a PSBT, populating the full Taproot tree for both
+// inputs: the VTXO (a 2-of-2 MuSig2(operator, VTXOUserPubKey) output with
+// an operator sweep path after VTXOExpiry, matching vtxo_tree.go's leaf
+// construction) when the caller supplies VTXOUserPubKey, and the
+// connector anchor (a single operator-only leaf, matching
+// BuildCommitmentTx's connector output).
+func (tb *TxBuilder) BuildForfeitPSBT(params *ForfeitTxParams) (*psbt.Packet, error) {
+	tx, err := tb.BuildForfeitTx(params)
+	if err != nil {
+		return nil, err
+	}
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The VTXO input is bound to this specific forfeit (and the
+	// commitment/connector it settles against), so it signs with
+	// SIGHASH_ALL|ANYONECANPAY rather than the package's usual default.
+	packet.Inputs[0].SighashType = txscript.SigHashAll | txscript.SigHashAnyOneCanPay
+
+	if params.VTXOUserPubKey != nil {
+		scriptKey, err := MuSig2AggregateKeys(params.OperatorPubKey, params.VTXOUserPubKey)
+		if err != nil {
+			return nil, err
+		}
+		sweepScript, err := BuildCheckSigWithAbsTimelockScript(params.OperatorPubKey, params.VTXOExpiry)
+		if err != nil {
+			return nil, err
+		}
+		info, err := NewTaprootSpendInfo(scriptKey, [][]byte{sweepScript})
+		if err != nil {
+			return nil, err
+		}
+		if err := attachTaprootLeaf(&packet.Inputs[0], info, sweepScript); err != nil {
+			return nil, err
+		}
+		attachMuSig2ParticipantPubKeys(&packet.Inputs[0], params.OperatorPubKey, params.VTXOUserPubKey)
+		packet.Inputs[0].WitnessUtxo = wire.NewTxOut(params.VTXO.Amount, info.ScriptPubKey())
+	} else {
+		attachWitnessUtxo(packet, 0, params.VTXO)
+	}
+
+	connectorScript, err := BuildCheckSigScript(params.OperatorPubKey)
+	if err != nil {
+		return nil, err
+	}
+	connectorInfo, err := NewTaprootSpendInfo(nil, [][]byte{connectorScript})
+	if err != nil {
+		return nil, err
+	}
+	if err := attachTaprootLeaf(&packet.Inputs[1], connectorInfo, connectorScript); err != nil {
+		return nil, err
+	}
+	packet.Inputs[1].SighashType = txscript.SigHashAll
+	packet.Inputs[1].WitnessUtxo = wire.NewTxOut(params.ConnectorAnchor.Amount, connectorInfo.ScriptPubKey())
+
+	var connectorIndexValue [4]byte
+	binary.BigEndian.PutUint32(connectorIndexValue[:], params.ConnectorAnchor.OutputIndex)
+	packet.Inputs[1].Unknowns = append(packet.Inputs[1].Unknowns, &psbt.Unknown{
+		Key:   arkProprietaryKey(psbtArkConnectorIndex),
+		Value: connectorIndexValue[:],
+	})
+
+	return packet, nil
+}
+
+// Combine merges the MuSig2 proprietary fields and Taproot signatures
+// that independently-produced copies of the same unsigned transaction's
+// PSBT carry, so each cosigner can sign their own copy and hand it back
+// for aggregation.
+func Combine(psbts ...*psbt.Packet) (*psbt.Packet, error) {
+	if len(psbts) == 0 {
+		return nil, errors.New("at least one psbt is required")
+	}
+
+	base := psbts[0]
+	baseTxid := base.UnsignedTx.TxHash()
+
+	for _, p := range psbts[1:] {
+		if p == nil {
+			return nil, errors.New("psbt is nil")
+		}
+		if p.UnsignedTx.TxHash() != baseTxid {
+			return nil, errors.New("all psbts must share the same unsigned transaction")
+		}
+
+		for i := range p.Inputs {
+			if err := checkNoConflictingInputFields(base.Inputs[i], p.Inputs[i]); err != nil {
+				return nil, err
+			}
+
+			base.Inputs[i].Unknowns = append(base.Inputs[i].Unknowns, p.Inputs[i].Unknowns...)
+			base.Inputs[i].TaprootScriptSpendSig = append(base.Inputs[i].TaprootScriptSpendSig, p.Inputs[i].TaprootScriptSpendSig...)
+			if len(base.Inputs[i].TaprootKeySpendSig) == 0 {
+				base.Inputs[i].TaprootKeySpendSig = p.Inputs[i].TaprootKeySpendSig
+			}
+		}
+	}
+
+	return base, nil
+}
+
+// checkNoConflictingInputFields rejects merging two copies of the same
+// input whose witness UTXO or leaf scripts disagree, per BIP-174's rule
+// that a combiner must never silently pick one of two conflicting values
+// for the same field.
+func checkNoConflictingInputFields(base, other psbt.PInput) error {
+	if base.WitnessUtxo != nil && other.WitnessUtxo != nil {
+		if base.WitnessUtxo.Value != other.WitnessUtxo.Value || !bytes.Equal(base.WitnessUtxo.PkScript, other.WitnessUtxo.PkScript) {
+			return errors.New("conflicting witness UTXO fields for the same input")
+		}
+	}
+
+	for _, baseLeaf := range base.TaprootLeafScript {
+		for _, otherLeaf := range other.TaprootLeafScript {
+			if !bytes.Equal(tapLeafHash(baseLeaf.Script), tapLeafHash(otherLeaf.Script)) {
+				continue
+			}
+			if !bytes.Equal(baseLeaf.ControlBlock, otherLeaf.ControlBlock) {
+				return errors.New("conflicting taproot leaf script fields for the same input")
+			}
+		}
+	}
+
+	return nil
+}
+
+// Finalize assembles each input's witness from whatever Taproot
+// signatures the packet carries (a key-path signature if present,
+// otherwise the signed leaf giving the smallest witness) and
+// returns the fully witness-populated transaction. Witness data doesn't
+// affect a transaction's txid, so the result always matches the txid the
+// direct BuildXxxTx path produces for the same parameters.
+func Finalize(packet *psbt.Packet) (*wire.MsgTx, error) {
+	if packet == nil {
+		return nil, errors.New("psbt packet is required")
+	}
+
+	tx := packet.UnsignedTx.Copy()
+	for i := range packet.Inputs {
+		witness, err := finalizeTaprootWitness(packet.Inputs[i])
+		if err != nil {
+			return nil, err
+		}
+		tx.TxIn[i].Witness = witness
+	}
+
+	return tx, nil
+}
+
+// finalizeTaprootWitness assembles a single input's witness stack from
+// its PSBT fields. When more than one leaf is fully signed (e.g. a
+// cooperative path and a timeout path both have valid signatures
+// attached), the smallest resulting witness is preferred, since it's the
+// cheapest to relay and mine.
+func finalizeTaprootWitness(input psbt.PInput) (wire.TxWitness, error) {
+	if len(input.TaprootKeySpendSig) > 0 {
+		return wire.TxWitness{input.TaprootKeySpendSig}, nil
+	}
+
+	var best wire.TxWitness
+	for _, leaf := range input.TaprootLeafScript {
+		leafHash := tapLeafHash(leaf.Script)
+		for _, sig := range input.TaprootScriptSpendSig {
+			if !bytes.Equal(sig.LeafHash, leafHash) {
+				continue
+			}
+			witness := AssembleTaprootScriptWitness([][]byte{sig.Signature}, leaf.Script, leaf.ControlBlock)
+			if best == nil || witnessSize(witness) < witnessSize(best) {
+				best = witness
+			}
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+
+	return nil, errors.New("no complete taproot signature found for this input")
+}
+
+// witnessSize returns the total byte length of witness's stack items.
+func witnessSize(witness wire.TxWitness) int {
+	size := 0
+	for _, item := range witness {
+		size += len(item)
+	}
+	return size
+}
@@ -0,0 +1,147 @@
+package arkbuilders
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBoardingPSBTFinalizeMatchesDirectBuild verifies that finalizing a
+// PSBT built by BuildBoardingPSBT (with a dummy key-path signature
+// attached) yields the same txid as BuildBoardingTx for the same
+// parameters.
+func TestBoardingPSBTFinalizeMatchesDirectBuild(t *testing.T) {
+	builder := NewTxBuilder()
+	userPrivKey := createTestPrivKey(t, 0x01)
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+
+	params := &BoardingTxParams{
+		FundingUTXO:    createTestUTXO(100000, 0),
+		Amount:         90000,
+		UserPubKey:     userPrivKey.PubKey(),
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		TimeoutBlocks:  144,
+		FeeRate:        1,
+	}
+
+	directTx, err := builder.BuildBoardingTx(params)
+	require.NoError(t, err)
+
+	packet, err := builder.BuildBoardingPSBT(params)
+	require.NoError(t, err)
+	require.NotNil(t, packet.Inputs[0].TaprootInternalKey)
+	require.NotNil(t, packet.Inputs[0].WitnessUtxo)
+	require.Equal(t, params.FundingUTXO.Amount, packet.Inputs[0].WitnessUtxo.Value)
+	require.Equal(t, txscript.SigHashDefault, packet.Inputs[0].SighashType)
+	require.Len(t, packet.Inputs[0].Unknowns, 1)
+
+	packet.Inputs[0].TaprootKeySpendSig = make([]byte, 64)
+
+	finalTx, err := Finalize(packet)
+	require.NoError(t, err)
+	require.Equal(t, directTx.TxHash(), finalTx.TxHash())
+}
+
+// TestForfeitPSBTRoundTrip verifies that BuildForfeitPSBT attaches a
+// complete Taproot leaf script for the VTXO and connector inputs and
+// that Combine+Finalize can reassemble a two-party signing session into
+// a witness-populated transaction with the same txid as BuildForfeitTx.
+func TestForfeitPSBTRoundTrip(t *testing.T) {
+	builder := NewTxBuilder()
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+	vtxoUserPrivKey := createTestPrivKey(t, 0x03)
+
+	params := &ForfeitTxParams{
+		VTXO:            createTestUTXO(50000, 0),
+		ConnectorAnchor: createTestUTXO(1000, 1),
+		OperatorPubKey:  operatorPrivKey.PubKey(),
+		FeeRate:         1,
+		VTXOUserPubKey:  vtxoUserPrivKey.PubKey(),
+		VTXOExpiry:      600000,
+	}
+
+	directTx, err := builder.BuildForfeitTx(params)
+	require.NoError(t, err)
+
+	packetA, err := builder.BuildForfeitPSBT(params)
+	require.NoError(t, err)
+	require.NotEmpty(t, packetA.Inputs[0].TaprootLeafScript)
+	require.NotEmpty(t, packetA.Inputs[1].TaprootLeafScript)
+	require.Len(t, packetA.Inputs[0].Unknowns, 1)
+	require.NotNil(t, packetA.Inputs[0].WitnessUtxo)
+	require.NotNil(t, packetA.Inputs[1].WitnessUtxo)
+	require.Equal(t, txscript.SigHashAll|txscript.SigHashAnyOneCanPay, packetA.Inputs[0].SighashType)
+	require.Equal(t, txscript.SigHashAll, packetA.Inputs[1].SighashType)
+	require.Len(t, packetA.Inputs[1].Unknowns, 1)
+
+	packetB, err := builder.BuildForfeitPSBT(params)
+	require.NoError(t, err)
+
+	packetA.Inputs[0].TaprootKeySpendSig = make([]byte, 64)
+	packetB.Inputs[1].TaprootKeySpendSig = make([]byte, 64)
+
+	combined, err := Combine(packetA, packetB)
+	require.NoError(t, err)
+
+	finalTx, err := Finalize(combined)
+	require.NoError(t, err)
+	require.Equal(t, directTx.TxHash(), finalTx.TxHash())
+}
+
+// TestCommitmentPSBTFinalizeMatchesDirectBuild verifies that
+// BuildCommitmentPSBT attaches a witness UTXO to every input from its
+// matching original UTXO (even though BuildCommitmentTx reorders inputs)
+// and the participant and batch expiry proprietary fields to the batch
+// output, and that finalizing the PSBT (with
+// dummy key-path signatures attached) yields the same txid as
+// BuildCommitmentTx for the same parameters.
+func TestCommitmentPSBTFinalizeMatchesDirectBuild(t *testing.T) {
+	builder := NewTxBuilder()
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+	userPrivKey := createTestPrivKey(t, 0x03)
+
+	params := &CommitmentTxParams{
+		OperatorUTXOs: []*UTXO{
+			createTestUTXO(500000, 0),
+		},
+		BoardingOutputs: []*UTXO{
+			createTestUTXO(100000, 1),
+		},
+		BatchAmount:     400000,
+		ConnectorAmount: 1000,
+		OperatorPubKey:  operatorPrivKey.PubKey(),
+		UserPubKeys:     []*btcec.PublicKey{userPrivKey.PubKey()},
+		BatchExpiry:     800000,
+		FeeRate:         1,
+	}
+
+	directTx, err := builder.BuildCommitmentTx(params)
+	require.NoError(t, err)
+
+	packet, err := builder.BuildCommitmentPSBT(params)
+	require.NoError(t, err)
+	require.Len(t, packet.Inputs, 2)
+	require.Equal(t, txscript.SigHashDefault, packet.Inputs[0].SighashType)
+	for i, txIn := range packet.UnsignedTx.TxIn {
+		require.NotNil(t, packet.Inputs[i].WitnessUtxo)
+		found := false
+		for _, utxo := range append(append([]*UTXO{}, params.OperatorUTXOs...), params.BoardingOutputs...) {
+			if utxo.TxHash == txIn.PreviousOutPoint.Hash && utxo.OutputIndex == txIn.PreviousOutPoint.Index {
+				require.Equal(t, utxo.Amount, packet.Inputs[i].WitnessUtxo.Value)
+				found = true
+			}
+		}
+		require.True(t, found, "input %d should match one of the original UTXOs", i)
+	}
+	require.Len(t, packet.Outputs[0].Unknowns, 2) // MuSig2 participant pubkeys + batch expiry
+
+	for i := range packet.Inputs {
+		packet.Inputs[i].TaprootKeySpendSig = make([]byte, 64)
+	}
+
+	finalTx, err := Finalize(packet)
+	require.NoError(t, err)
+	require.Equal(t, directTx.TxHash(), finalTx.TxHash())
+}
@@ -0,0 +1,108 @@
+package arkbuilders
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// SignerSession wraps a single cosigner's in-progress copy of a forfeit
+// or commitment PSBT (as produced by BuildForfeitPSBT/BuildCommitmentPSBT),
+// exposing the minimal surface a multi-party signing round needs: attach
+// this cosigner's own tapscript partial signatures, combine with other
+// cosigners' sessions, and finalize once enough signatures are present.
+type SignerSession struct {
+	packet *psbt.Packet
+}
+
+// NewSignerSession wraps packet in a SignerSession for a single cosigner's
+// signing round.
+func NewSignerSession(packet *psbt.Packet) (*SignerSession, error) {
+	if packet == nil {
+		return nil, errors.New("psbt packet is required")
+	}
+	return &SignerSession{packet: packet}, nil
+}
+
+// AddPartialSig verifies sig against inputIdx's leaf script (identified
+// by leafHash) using the input's own sighash type and witness UTXO, then
+// attaches it as a BIP-371 tapscript partial signature. Verifying before
+// attaching keeps a combiner from ever merging a signature it couldn't
+// itself confirm is valid, per BIP-174.
+func (s *SignerSession) AddPartialSig(inputIdx int, pubKey *btcec.PublicKey, sig []byte, leafHash []byte) error {
+	if inputIdx < 0 || inputIdx >= len(s.packet.Inputs) {
+		return errors.New("input index out of range")
+	}
+	input := &s.packet.Inputs[inputIdx]
+	if input.WitnessUtxo == nil {
+		return errors.New("input is missing its witness UTXO")
+	}
+
+	var leafScript []byte
+	for _, leaf := range input.TaprootLeafScript {
+		if bytes.Equal(tapLeafHash(leaf.Script), leafHash) {
+			leafScript = leaf.Script
+			break
+		}
+	}
+	if leafScript == nil {
+		return errors.New("no leaf script matches the given leaf hash")
+	}
+
+	sighashType := input.SighashType
+	if sighashType == 0 {
+		sighashType = txscript.SigHashDefault
+	}
+
+	rawSig := sig
+	if sighashType != txscript.SigHashDefault && len(rawSig) == 65 {
+		rawSig = rawSig[:64]
+	}
+	parsedSig, err := schnorr.ParseSignature(rawSig)
+	if err != nil {
+		return err
+	}
+
+	prevFetcher := txscript.NewCannedPrevOutputFetcher(input.WitnessUtxo.PkScript, input.WitnessUtxo.Value)
+	sigHashes := txscript.NewTxSigHashes(s.packet.UnsignedTx, prevFetcher)
+	sigHash, err := txscript.CalcTapscriptSignaturehash(sigHashes, sighashType, s.packet.UnsignedTx, inputIdx, prevFetcher, txscript.NewBaseTapLeaf(leafScript))
+	if err != nil {
+		return err
+	}
+	if !parsedSig.Verify(sigHash, pubKey) {
+		return errors.New("partial signature does not verify against its leaf script")
+	}
+
+	input.TaprootScriptSpendSig = append(input.TaprootScriptSpendSig, &psbt.TaprootScriptSpendSig{
+		XOnlyPubKey: schnorr.SerializePubKey(pubKey),
+		LeafHash:    leafHash,
+		Signature:   sig,
+		SigHash:     sighashType,
+	})
+	return nil
+}
+
+// Combine merges other's partial signatures into a new session, via the
+// package-level Combine (which rejects conflicting UTXO/witness-script
+// fields between the two packets).
+func (s *SignerSession) Combine(other *SignerSession) (*SignerSession, error) {
+	if other == nil {
+		return nil, errors.New("other session is required")
+	}
+	combined, err := Combine(s.packet, other.packet)
+	if err != nil {
+		return nil, err
+	}
+	return &SignerSession{packet: combined}, nil
+}
+
+// Finalize assembles the fully-signed transaction via the package-level
+// Finalize.
+func (s *SignerSession) Finalize() (*wire.MsgTx, error) {
+	return Finalize(s.packet)
+}
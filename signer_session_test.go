@@ -0,0 +1,136 @@
+package arkbuilders
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// signTapscriptLeaf computes inputIdx's tapscript sighash for tx against
+// witnessUTXO and leafScript, signs it with privKey, and appends the
+// trailing sighash byte when sighashType isn't SIGHASH_DEFAULT, matching
+// the witness format finalizeTaprootWitness expects.
+func signTapscriptLeaf(t *testing.T, tx *wire.MsgTx, inputIdx int, witnessUTXO *UTXO, leafScript []byte, sighashType txscript.SigHashType, privKey *btcec.PrivateKey) []byte {
+	t.Helper()
+	prevFetcher := txscript.NewCannedPrevOutputFetcher(witnessUTXO.ScriptPubKey, witnessUTXO.Amount)
+	sigHashes := txscript.NewTxSigHashes(tx, prevFetcher)
+	sigHash, err := txscript.CalcTapscriptSignaturehash(sigHashes, sighashType, tx, inputIdx, prevFetcher, txscript.NewBaseTapLeaf(leafScript))
+	require.NoError(t, err)
+
+	signature, err := schnorr.Sign(privKey, sigHash)
+	require.NoError(t, err)
+	sig := signature.Serialize()
+	if sighashType != txscript.SigHashDefault {
+		sig = append(sig, byte(sighashType))
+	}
+	return sig
+}
+
+// TestSignerSessionTwoPartyForfeit verifies that two independently
+// signed SignerSessions over the same forfeit PSBT - one with the VTXO
+// input's partial signature attached, the other with the connector
+// input's - combine and finalize into the same transaction
+// BuildForfeitTx produces directly.
+func TestSignerSessionTwoPartyForfeit(t *testing.T) {
+	builder := NewTxBuilder()
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+	vtxoUserPrivKey := createTestPrivKey(t, 0x03)
+
+	params := &ForfeitTxParams{
+		VTXO:            createTestUTXO(50000, 0),
+		ConnectorAnchor: createTestUTXO(1000, 1),
+		OperatorPubKey:  operatorPrivKey.PubKey(),
+		FeeRate:         1,
+		VTXOUserPubKey:  vtxoUserPrivKey.PubKey(),
+		VTXOExpiry:      600000,
+	}
+
+	// createTestUTXO leaves ScriptPubKey unset; BuildForfeitPSBT computes
+	// the real witness scripts internally rather than trusting the
+	// caller's, but signTapscriptLeaf below needs the VTXO/connector's
+	// actual ScriptPubKey to compute a sighash that verifies, so set them
+	// here the same way BuildForfeitPSBT does.
+	vtxoScriptKey, err := MuSig2AggregateKeys(params.OperatorPubKey, params.VTXOUserPubKey)
+	require.NoError(t, err)
+	sweepScript, err := BuildCheckSigWithAbsTimelockScript(params.OperatorPubKey, params.VTXOExpiry)
+	require.NoError(t, err)
+	vtxoInfo, err := NewTaprootSpendInfo(vtxoScriptKey, [][]byte{sweepScript})
+	require.NoError(t, err)
+	params.VTXO.ScriptPubKey = vtxoInfo.ScriptPubKey()
+
+	connectorScript, err := BuildCheckSigScript(params.OperatorPubKey)
+	require.NoError(t, err)
+	connectorInfo, err := NewTaprootSpendInfo(nil, [][]byte{connectorScript})
+	require.NoError(t, err)
+	params.ConnectorAnchor.ScriptPubKey = connectorInfo.ScriptPubKey()
+
+	directTx, err := builder.BuildForfeitTx(params)
+	require.NoError(t, err)
+
+	packetA, err := builder.BuildForfeitPSBT(params)
+	require.NoError(t, err)
+	sessionA, err := NewSignerSession(packetA)
+	require.NoError(t, err)
+
+	vtxoSig := signTapscriptLeaf(t, packetA.UnsignedTx, 0, params.VTXO, sweepScript, txscript.SigHashAll|txscript.SigHashAnyOneCanPay, operatorPrivKey)
+	require.NoError(t, sessionA.AddPartialSig(0, params.OperatorPubKey, vtxoSig, TapLeafHash(sweepScript)))
+
+	packetB, err := builder.BuildForfeitPSBT(params)
+	require.NoError(t, err)
+	sessionB, err := NewSignerSession(packetB)
+	require.NoError(t, err)
+
+	connectorSig := signTapscriptLeaf(t, packetB.UnsignedTx, 1, params.ConnectorAnchor, connectorScript, txscript.SigHashAll, operatorPrivKey)
+	require.NoError(t, sessionB.AddPartialSig(1, params.OperatorPubKey, connectorSig, TapLeafHash(connectorScript)))
+
+	// A bad signature must be rejected rather than silently attached.
+	// Tamper with a genuinely valid signature rather than reusing
+	// connectorSig as-is, since BuildForfeitPSBT is deterministic and an
+	// untampered reuse would verify and be accepted.
+	tamperedSig := append([]byte(nil), connectorSig...)
+	tamperedSig[0] ^= 0xff
+	err = sessionA.AddPartialSig(1, params.OperatorPubKey, tamperedSig, TapLeafHash(connectorScript))
+	require.Error(t, err)
+
+	combined, err := sessionA.Combine(sessionB)
+	require.NoError(t, err)
+
+	finalTx, err := combined.Finalize()
+	require.NoError(t, err)
+	require.Equal(t, directTx.TxHash(), finalTx.TxHash())
+	require.NotEmpty(t, finalTx.TxIn[0].Witness)
+	require.NotEmpty(t, finalTx.TxIn[1].Witness)
+}
+
+// TestSignerSessionCombineRejectsConflictingUTXO verifies that Combine
+// refuses to merge two sessions whose witness UTXO disagrees for the
+// same input, per BIP-174.
+func TestSignerSessionCombineRejectsConflictingUTXO(t *testing.T) {
+	builder := NewTxBuilder()
+	operatorPrivKey := createTestPrivKey(t, 0x02)
+
+	params := &ForfeitTxParams{
+		VTXO:            createTestUTXO(50000, 0),
+		ConnectorAnchor: createTestUTXO(1000, 1),
+		OperatorPubKey:  operatorPrivKey.PubKey(),
+		FeeRate:         1,
+	}
+
+	packetA, err := builder.BuildForfeitPSBT(params)
+	require.NoError(t, err)
+	sessionA, err := NewSignerSession(packetA)
+	require.NoError(t, err)
+
+	packetB, err := builder.BuildForfeitPSBT(params)
+	require.NoError(t, err)
+	packetB.Inputs[0].WitnessUtxo.Value += 1
+	sessionB, err := NewSignerSession(packetB)
+	require.NoError(t, err)
+
+	_, err = sessionA.Combine(sessionB)
+	require.Error(t, err)
+}
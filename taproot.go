@@ -74,26 +74,13 @@ func MuSig2AggregateKeys(pubKeys ...*btcec.PublicKey) (*btcec.PublicKey, error)
 	return btcec.NewPublicKey(&aggPoint.X, &aggPoint.Y), nil
 }
 
-// CreateTaprootScript creates a Taproot output script with script paths
+// CreateTaprootScript creates a Taproot output script with script paths.
+// Callers that also need the tree structure to build control blocks for
+// script-path spends should use NewTaprootSpendInfo instead.
 func CreateTaprootScript(internalPubKey *btcec.PublicKey, scripts [][]byte) ([]byte, error) {
-	// If internal key is nil, use unspendable key (point at infinity represented by specific value)
-	var internalKey *btcec.PublicKey
-	if internalPubKey == nil {
-		// Use "NUMS" point (Nothing Up My Sleeve) - unspendable internal key
-		// This is a standard way to create an unspendable keypath
-		numsPoint := []byte{
-			0x50, 0x92, 0x9b, 0x74, 0xc1, 0xa0, 0x49, 0x54,
-			0xb7, 0x8b, 0x4b, 0x60, 0x35, 0xe9, 0x7a, 0x5e,
-			0x07, 0x8a, 0x5a, 0x0f, 0x28, 0xec, 0x96, 0xd5,
-			0x47, 0xbf, 0xee, 0x9a, 0xce, 0x80, 0x3a, 0xc0,
-		}
-		var err error
-		internalKey, err = schnorr.ParsePubKey(numsPoint)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		internalKey = internalPubKey
+	internalKey, err := resolveTaprootInternalKey(internalPubKey)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build the tapscript tree
@@ -155,6 +142,13 @@ func tapLeafHash(script []byte) []byte {
 	return taggedHash("TapLeaf", buf.Bytes())
 }
 
+// TapLeafHash is the exported form of tapLeafHash, for packages outside
+// arkbuilders (e.g. the psbt subpackage) that need to match a tapscript
+// leaf against its BIP-341 leaf hash without re-deriving it.
+func TapLeafHash(script []byte) []byte {
+	return tapLeafHash(script)
+}
+
 // tapBranchHash computes the branch hash for two child nodes
 func tapBranchHash(left, right []byte) []byte {
 	// TapBranch = TaggedHash("TapBranch", left || right)
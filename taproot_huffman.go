@@ -0,0 +1,121 @@
+package arkbuilders
+
+import (
+	"container/heap"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// TapLeaf is a tapscript leaf with a relative usage weight, used to build
+// a Huffman-weighted script tree where heavier leaves end up shallower.
+type TapLeaf struct {
+	Script []byte
+	Weight uint64
+}
+
+// BuildHuffmanTapscriptTree builds a Taproot script tree under
+// internalPubKey (or the NUMS point if nil) using a Huffman coding
+// strategy: the two lowest-weight subtrees are repeatedly combined until
+// one remains, so leaves weighted as "hot path" (e.g. a cooperative
+// MuSig2 exit) end up shallower than rarely-used leaves (e.g. a timelock
+// refund), shrinking the control block those hot leaves need at spend
+// time. Ties are broken by insertion order for determinism.
+func BuildHuffmanTapscriptTree(internalPubKey *btcec.PublicKey, leaves []TapLeaf) (*TaprootSpendInfo, error) {
+	internalKey, err := resolveTaprootInternalKey(internalPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(leaves) == 0 {
+		return newTaprootSpendInfo(internalKey, nil, nil, nil)
+	}
+
+	scripts := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		scripts[i] = leaf.Script
+	}
+
+	root, paths := buildHuffmanTreeWithPaths(leaves)
+	return newTaprootSpendInfo(internalKey, root, scripts, paths)
+}
+
+// huffmanNode is one subtree in the Huffman construction: its combined
+// tapscript hash, the running weight of everything under it, the leaf
+// indices it covers (for path-tracking), and its original insertion
+// index (for deterministic tie-breaking in the min-heap).
+type huffmanNode struct {
+	hash     []byte
+	weight   uint64
+	leafIdxs []int
+	seq      int
+}
+
+// huffmanHeap is a min-heap of huffmanNodes ordered by weight, breaking
+// ties by insertion order so BuildHuffmanTapscriptTree is deterministic
+// regardless of the underlying sort's stability.
+type huffmanHeap []*huffmanNode
+
+func (h huffmanHeap) Len() int { return len(h) }
+func (h huffmanHeap) Less(i, j int) bool {
+	if h[i].weight != h[j].weight {
+		return h[i].weight < h[j].weight
+	}
+	return h[i].seq < h[j].seq
+}
+func (h huffmanHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *huffmanHeap) Push(x interface{}) { *h = append(*h, x.(*huffmanNode)) }
+func (h *huffmanHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	node := old[n-1]
+	*h = old[:n-1]
+	return node
+}
+
+// buildHuffmanTreeWithPaths pushes every leaf as a single-node subtree
+// into a min-heap keyed by weight, then repeatedly pops the two
+// lowest-weight subtrees, combines them with tapBranchHash, and pushes
+// the result back with summed weight, recording each leaf's sibling hash
+// at every combine step along the way.
+func buildHuffmanTreeWithPaths(leaves []TapLeaf) ([]byte, [][][]byte) {
+	paths := make([][][]byte, len(leaves))
+
+	h := make(huffmanHeap, len(leaves))
+	for i, leaf := range leaves {
+		h[i] = &huffmanNode{
+			hash:     tapLeafHash(leaf.Script),
+			weight:   leaf.Weight,
+			leafIdxs: []int{i},
+			seq:      i,
+		}
+	}
+	heap.Init(&h)
+
+	nextSeq := len(leaves)
+	for h.Len() > 1 {
+		left := heap.Pop(&h).(*huffmanNode)
+		right := heap.Pop(&h).(*huffmanNode)
+
+		for _, leafIdx := range left.leafIdxs {
+			paths[leafIdx] = append(paths[leafIdx], right.hash)
+		}
+		for _, leafIdx := range right.leafIdxs {
+			paths[leafIdx] = append(paths[leafIdx], left.hash)
+		}
+
+		merged := make([]int, 0, len(left.leafIdxs)+len(right.leafIdxs))
+		merged = append(merged, left.leafIdxs...)
+		merged = append(merged, right.leafIdxs...)
+
+		heap.Push(&h, &huffmanNode{
+			hash:     tapBranchHash(left.hash, right.hash),
+			weight:   left.weight + right.weight,
+			leafIdxs: merged,
+			seq:      nextSeq,
+		})
+		nextSeq++
+	}
+
+	root := heap.Pop(&h).(*huffmanNode)
+	return root.hash, paths
+}
@@ -0,0 +1,68 @@
+package arkbuilders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestHuffmanTapscriptTreeDeterminism verifies that building the same
+// leaf slice repeatedly always produces the same merkle root, since ties
+// are broken by a stable insertion-order sequence rather than map
+// iteration or sort instability.
+func TestHuffmanTapscriptTreeDeterminism(t *testing.T) {
+	userPubKey := createTestPrivKey(t, 0x01).PubKey()
+
+	script1, err := BuildCheckSigScript(userPubKey)
+	require.NoError(t, err)
+	script2, err := BuildCheckSigWithTimelockScript(userPubKey, 144)
+	require.NoError(t, err)
+	script3, err := BuildCheckSigWithAbsTimelockScript(userPubKey, 800000)
+	require.NoError(t, err)
+
+	leaves := []TapLeaf{
+		{Script: script1, Weight: 100},
+		{Script: script2, Weight: 1},
+		{Script: script3, Weight: 1},
+	}
+
+	var roots [][]byte
+	for i := 0; i < 10; i++ {
+		info, err := BuildHuffmanTapscriptTree(userPubKey, leaves)
+		require.NoError(t, err)
+		roots = append(roots, info.MerkleRoot())
+	}
+	for i, root := range roots {
+		require.Equal(t, roots[0], root, "run %d produced a different root", i)
+	}
+}
+
+// TestHuffmanTapscriptTreePrioritizesWeight verifies that the
+// heaviest-weighted leaf ends up at depth 1 (a single sibling hash in
+// its control block) while light leaves are pushed deeper.
+func TestHuffmanTapscriptTreePrioritizesWeight(t *testing.T) {
+	userPubKey := createTestPrivKey(t, 0x01).PubKey()
+
+	hotScript, err := BuildCheckSigScript(userPubKey)
+	require.NoError(t, err)
+	coldScript1, err := BuildCheckSigWithTimelockScript(userPubKey, 144)
+	require.NoError(t, err)
+	coldScript2, err := BuildCheckSigWithAbsTimelockScript(userPubKey, 800000)
+	require.NoError(t, err)
+
+	leaves := []TapLeaf{
+		{Script: hotScript, Weight: 1000},
+		{Script: coldScript1, Weight: 1},
+		{Script: coldScript2, Weight: 1},
+	}
+
+	info, err := BuildHuffmanTapscriptTree(userPubKey, leaves)
+	require.NoError(t, err)
+
+	hotCB, err := info.ControlBlock(hotScript)
+	require.NoError(t, err)
+	coldCB, err := info.ControlBlock(coldScript1)
+	require.NoError(t, err)
+
+	require.Less(t, len(hotCB), len(coldCB), "the heaviest leaf should have a shallower (shorter) control block")
+}
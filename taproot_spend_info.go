@@ -0,0 +1,218 @@
+package arkbuilders
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TaprootSpendInfo describes everything needed to spend a Taproot output
+// built by NewTaprootSpendInfo: the internal and tweaked output keys, the
+// script tree's merkle root, and enough of the tree structure to produce a
+// BIP-341 control block for any leaf script in it.
+type TaprootSpendInfo struct {
+	internalKey     *btcec.PublicKey
+	outputKey       *btcec.PublicKey
+	outputKeyParity bool
+	merkleRoot      []byte
+	scriptPubKey    []byte
+
+	// controlBlocks maps a leaf script (by its raw bytes) to its
+	// precomputed control block.
+	controlBlocks map[string][]byte
+}
+
+// NewTaprootSpendInfo builds the Taproot script tree for scripts under
+// internalPubKey (or the NUMS point if nil) and retains, for every leaf,
+// the sibling path needed to assemble its BIP-341 control block. This is
+// the tree-aware counterpart to CreateTaprootScript, which only returns
+// the resulting scriptPubKey and discards the tree.
+func NewTaprootSpendInfo(internalPubKey *btcec.PublicKey, scripts [][]byte) (*TaprootSpendInfo, error) {
+	internalKey, err := resolveTaprootInternalKey(internalPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scripts) == 0 {
+		// No script paths: key-path only, matching CreateTaprootScript's
+		// existing (untweaked) behavior for this case.
+		return newTaprootSpendInfo(internalKey, nil, nil, nil)
+	}
+
+	root, paths := buildTapTreeWithPaths(scripts)
+	return newTaprootSpendInfo(internalKey, root, scripts, paths)
+}
+
+// newTaprootSpendInfo assembles a TaprootSpendInfo from an already-built
+// tree: its merkle root and, for every leaf script, the sibling path
+// (deepest first) recorded while combining that tree. Passing a nil root
+// builds a key-path-only TaprootSpendInfo. Shared by NewTaprootSpendInfo
+// (naive left-to-right tree) and BuildHuffmanTapscriptTree (weighted
+// tree), which only differ in how they pair leaves.
+func newTaprootSpendInfo(internalKey *btcec.PublicKey, root []byte, scripts [][]byte, paths [][][]byte) (*TaprootSpendInfo, error) {
+	info := &TaprootSpendInfo{
+		internalKey:   internalKey,
+		merkleRoot:    root,
+		controlBlocks: make(map[string][]byte),
+	}
+
+	outputKey := internalKey
+	if root != nil {
+		outputKey = txscript.ComputeTaprootOutputKey(internalKey, root)
+
+		internalKeyXOnly := schnorr.SerializePubKey(internalKey)
+		for i, script := range scripts {
+			controlBlock := make([]byte, 0, 1+32+len(paths[i])*32)
+			controlBlock = append(controlBlock, leafVersionAndParity(byte(txscript.BaseLeafVersion), outputKey))
+			controlBlock = append(controlBlock, internalKeyXOnly...)
+			for _, sibling := range paths[i] {
+				controlBlock = append(controlBlock, sibling...)
+			}
+			info.controlBlocks[string(script)] = controlBlock
+		}
+	}
+
+	info.outputKey = outputKey
+	info.outputKeyParity = isOddYCompressed(outputKey)
+
+	scriptPubKey, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_1).
+		AddData(schnorr.SerializePubKey(outputKey)).
+		Script()
+	if err != nil {
+		return nil, err
+	}
+	info.scriptPubKey = scriptPubKey
+
+	return info, nil
+}
+
+// OutputKey returns the tweaked Taproot output key (the x-only key
+// committed to in the scriptPubKey).
+func (info *TaprootSpendInfo) OutputKey() *btcec.PublicKey {
+	return info.outputKey
+}
+
+// TweakedInternalKey returns the internal key actually used to build the
+// tree (the NUMS point if the caller passed nil) along with the parity of
+// the resulting output key, which callers need to set the control block's
+// parity bit correctly.
+func (info *TaprootSpendInfo) TweakedInternalKey() (*btcec.PublicKey, bool) {
+	return info.internalKey, info.outputKeyParity
+}
+
+// MerkleRoot returns the tapscript tree's merkle root, or nil for a
+// key-path-only output.
+func (info *TaprootSpendInfo) MerkleRoot() []byte {
+	return info.merkleRoot
+}
+
+// ScriptPubKey returns the P2TR output script, identical to what
+// CreateTaprootScript would return for the same inputs.
+func (info *TaprootSpendInfo) ScriptPubKey() []byte {
+	return info.scriptPubKey
+}
+
+// ControlBlock returns the BIP-341 control block for spending via the
+// given leaf script:
+//
+//	(0xc0 | parity_of_Q) || internal_pubkey_xonly || sibling_hash_1 || ... || sibling_hash_n
+func (info *TaprootSpendInfo) ControlBlock(script []byte) ([]byte, error) {
+	cb, ok := info.controlBlocks[string(script)]
+	if !ok {
+		return nil, errors.New("script is not a leaf of this taproot tree")
+	}
+	return cb, nil
+}
+
+// AssembleTaprootScriptWitness lays out a script-path spending witness
+// stack in the order consensus expects: the leaf's input data, followed
+// by the leaf script itself, followed by the control block.
+func AssembleTaprootScriptWitness(stackItems [][]byte, script []byte, controlBlock []byte) wire.TxWitness {
+	witness := make(wire.TxWitness, 0, len(stackItems)+2)
+	witness = append(witness, stackItems...)
+	witness = append(witness, script)
+	witness = append(witness, controlBlock)
+	return witness
+}
+
+// resolveTaprootInternalKey returns internalPubKey, or the standard NUMS
+// (Nothing Up My Sleeve) point when internalPubKey is nil, matching the
+// unspendable-keypath convention CreateTaprootScript already uses.
+func resolveTaprootInternalKey(internalPubKey *btcec.PublicKey) (*btcec.PublicKey, error) {
+	if internalPubKey != nil {
+		return internalPubKey, nil
+	}
+
+	numsPoint := []byte{
+		0x50, 0x92, 0x9b, 0x74, 0xc1, 0xa0, 0x49, 0x54,
+		0xb7, 0x8b, 0x4b, 0x60, 0x35, 0xe9, 0x7a, 0x5e,
+		0x07, 0x8a, 0x5a, 0x0f, 0x28, 0xec, 0x96, 0xd5,
+		0x47, 0xbf, 0xee, 0x9a, 0xce, 0x80, 0x3a, 0xc0,
+	}
+	return schnorr.ParsePubKey(numsPoint)
+}
+
+// buildTapTreeWithPaths builds the same left-to-right binary tapscript
+// tree as buildTapscriptMerkleRoot, but additionally records, for each
+// leaf, the sibling hashes encountered on its path to the root (deepest
+// first), which is exactly the data a BIP-341 control block needs.
+func buildTapTreeWithPaths(scripts [][]byte) ([]byte, [][][]byte) {
+	type node struct {
+		hash     []byte
+		leafIdxs []int
+	}
+
+	nodes := make([]node, len(scripts))
+	paths := make([][][]byte, len(scripts))
+	for i, script := range scripts {
+		nodes[i] = node{hash: tapLeafHash(script), leafIdxs: []int{i}}
+	}
+
+	for len(nodes) > 1 {
+		var next []node
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 >= len(nodes) {
+				next = append(next, nodes[i])
+				continue
+			}
+
+			left, right := nodes[i], nodes[i+1]
+			combined := tapBranchHash(left.hash, right.hash)
+
+			for _, leafIdx := range left.leafIdxs {
+				paths[leafIdx] = append(paths[leafIdx], right.hash)
+			}
+			for _, leafIdx := range right.leafIdxs {
+				paths[leafIdx] = append(paths[leafIdx], left.hash)
+			}
+
+			merged := make([]int, 0, len(left.leafIdxs)+len(right.leafIdxs))
+			merged = append(merged, left.leafIdxs...)
+			merged = append(merged, right.leafIdxs...)
+			next = append(next, node{hash: combined, leafIdxs: merged})
+		}
+		nodes = next
+	}
+
+	return nodes[0].hash, paths
+}
+
+// leafVersionAndParity builds the first byte of a BIP-341 control block:
+// the leaf version with its low bit replaced by the output key's parity.
+func leafVersionAndParity(leafVersion byte, outputKey *btcec.PublicKey) byte {
+	if isOddYCompressed(outputKey) {
+		return leafVersion | 0x01
+	}
+	return leafVersion &^ 0x01
+}
+
+// isOddYCompressed reports whether pubKey's Y coordinate is odd, read off
+// its compressed encoding so callers don't need direct field access.
+func isOddYCompressed(pubKey *btcec.PublicKey) bool {
+	compressed := pubKey.SerializeCompressed()
+	return compressed[0] == 0x03
+}
@@ -0,0 +1,77 @@
+package arkbuilders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTaprootSpendInfoControlBlock verifies that NewTaprootSpendInfo
+// produces a scriptPubKey matching CreateTaprootScript, and a control
+// block per leaf that starts with the expected leaf-version/parity byte
+// and internal key, with one sibling hash per tree level.
+func TestTaprootSpendInfoControlBlock(t *testing.T) {
+	userPrivKey := createTestPrivKey(t, 0x01)
+	userPubKey := userPrivKey.PubKey()
+
+	script1, err := BuildCheckSigScript(userPubKey)
+	require.NoError(t, err)
+	script2, err := BuildCheckSigWithTimelockScript(userPubKey, 144)
+	require.NoError(t, err)
+	script3, err := BuildCheckSigWithAbsTimelockScript(userPubKey, 800000)
+	require.NoError(t, err)
+
+	scripts := [][]byte{script1, script2, script3}
+
+	info, err := NewTaprootSpendInfo(userPubKey, scripts)
+	require.NoError(t, err)
+
+	want, err := CreateTaprootScript(userPubKey, scripts)
+	require.NoError(t, err)
+	require.Equal(t, want, info.ScriptPubKey())
+
+	internalKey, parity := info.TweakedInternalKey()
+	require.Equal(t, userPubKey.SerializeCompressed(), internalKey.SerializeCompressed())
+
+	for _, script := range scripts {
+		cb, err := info.ControlBlock(script)
+		require.NoError(t, err)
+		require.True(t, len(cb) == 33+32 || len(cb) == 33+64, "unexpected control block length %d", len(cb))
+
+		wantFirstByte := byte(0xc0)
+		if parity {
+			wantFirstByte = 0xc1
+		}
+		require.Equal(t, wantFirstByte, cb[0])
+	}
+
+	_, err = info.ControlBlock([]byte("not a leaf of this tree"))
+	require.Error(t, err)
+}
+
+// TestTaprootSpendInfoKeyPathOnly verifies that a tree with no scripts
+// has a nil merkle root and that every input produces a valid witness
+// layout via AssembleTaprootScriptWitness for the script-path case.
+func TestTaprootSpendInfoKeyPathOnly(t *testing.T) {
+	userPrivKey := createTestPrivKey(t, 0x01)
+	userPubKey := userPrivKey.PubKey()
+
+	info, err := NewTaprootSpendInfo(userPubKey, nil)
+	require.NoError(t, err)
+	require.Nil(t, info.MerkleRoot())
+
+	script, err := BuildCheckSigScript(userPubKey)
+	require.NoError(t, err)
+
+	treeInfo, err := NewTaprootSpendInfo(userPubKey, [][]byte{script})
+	require.NoError(t, err)
+	require.NotNil(t, treeInfo.MerkleRoot())
+
+	cb, err := treeInfo.ControlBlock(script)
+	require.NoError(t, err)
+
+	witness := AssembleTaprootScriptWitness([][]byte{[]byte("sig")}, script, cb)
+	require.Len(t, witness, 3)
+	require.Equal(t, script, []byte(witness[1]))
+	require.Equal(t, cb, []byte(witness[2]))
+}
@@ -0,0 +1,90 @@
+package arkbuilders
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// TaprootTweakPublicKey applies the BIP-341 key-path tweak to internal,
+// returning the resulting output key together with its parity bit. It
+// first negates internal if its Y coordinate is odd, so the tweak is
+// always applied to the even-Y representative the x-only scriptPubKey
+// actually commits to, matching the convention txscript.
+// ComputeTaprootOutputKey uses internally but doesn't expose. Pass a nil
+// merkleRoot for a key-path-only (NUMS-free) output.
+func TaprootTweakPublicKey(internal *btcec.PublicKey, merkleRoot []byte) (*btcec.PublicKey, bool, error) {
+	if internal == nil {
+		return nil, false, errors.New("internal public key is required")
+	}
+
+	outputKey, parity, _, err := taprootTweakPubKeyScalar(internal, merkleRoot)
+	if err != nil {
+		return nil, false, err
+	}
+	return outputKey, parity, nil
+}
+
+// TaprootTweakSecretKey applies the BIP-341 key-path tweak to the secret
+// key side of internal: it negates the private scalar d to n-d if
+// internal's public key has odd Y (so it matches the x-only pubkey the
+// tweak is computed over), then returns d' = (d + t) mod n along with
+// the resulting output key's parity bit, so callers driving a
+// control-block-based signer know which parity to set.
+func TaprootTweakSecretKey(internal *btcec.PrivateKey, merkleRoot []byte) (*btcec.PrivateKey, bool, error) {
+	if internal == nil {
+		return nil, false, errors.New("internal private key is required")
+	}
+
+	_, parity, t, err := taprootTweakPubKeyScalar(internal.PubKey(), merkleRoot)
+	if err != nil {
+		return nil, false, err
+	}
+
+	d := internal.Key
+	if isOddYCompressed(internal.PubKey()) {
+		d.Negate()
+	}
+	d.Add(t)
+
+	return &btcec.PrivateKey{Key: d}, parity, nil
+}
+
+// taprootTweakPubKeyScalar is the shared implementation behind
+// TaprootTweakPublicKey: it normalizes internal to even-Y, computes
+// t = taggedHash("TapTweak", xonly(internal) || merkleRoot) rejecting an
+// out-of-range t, and returns the tweaked output key, its parity, and the
+// tweak scalar itself (needed by TaprootTweakSecretKey and reused by the
+// MuSig2 session context so the two never diverge).
+func taprootTweakPubKeyScalar(internal *btcec.PublicKey, merkleRoot []byte) (*btcec.PublicKey, bool, *btcec.ModNScalar, error) {
+	var p btcec.JacobianPoint
+	internal.AsJacobian(&p)
+	p.ToAffine()
+
+	if p.Y.IsOdd() {
+		p.Y.Negate(1)
+		p.Y.Normalize()
+	}
+
+	xonly := schnorr.SerializePubKey(btcec.NewPublicKey(&p.X, &p.Y))
+
+	var buf bytes.Buffer
+	buf.Write(xonly)
+	buf.Write(merkleRoot)
+	tDigest := taggedHash("TapTweak", buf.Bytes())
+
+	var t btcec.ModNScalar
+	if overflow := t.SetByteSlice(tDigest); overflow {
+		return nil, false, nil, errors.New("tweak hash is not a valid scalar")
+	}
+
+	var tPoint, outPoint btcec.JacobianPoint
+	btcec.ScalarBaseMultNonConst(&t, &tPoint)
+	btcec.AddNonConst(&p, &tPoint, &outPoint)
+	outPoint.ToAffine()
+
+	outputKey := btcec.NewPublicKey(&outPoint.X, &outPoint.Y)
+	return outputKey, outPoint.Y.IsOdd(), &t, nil
+}
@@ -0,0 +1,27 @@
+package arkbuilders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTaprootTweakSecretKeyMatchesPublicKey verifies that tweaking the
+// secret key side with TaprootTweakSecretKey yields a private key whose
+// public key equals what TaprootTweakPublicKey computes independently
+// from the internal public key, for both a key-path-only tweak and one
+// bound to a merkle root.
+func TestTaprootTweakSecretKeyMatchesPublicKey(t *testing.T) {
+	internal := createTestPrivKey(t, 0x07)
+
+	for _, merkleRoot := range [][]byte{nil, tapLeafHash([]byte{0x51})} {
+		tweakedPub, parityFromPub, err := TaprootTweakPublicKey(internal.PubKey(), merkleRoot)
+		require.NoError(t, err)
+
+		tweakedPriv, parityFromPriv, err := TaprootTweakSecretKey(internal, merkleRoot)
+		require.NoError(t, err)
+
+		require.Equal(t, parityFromPub, parityFromPriv)
+		require.Equal(t, tweakedPub.SerializeCompressed(), tweakedPriv.PubKey().SerializeCompressed())
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+
+	"github.com/utexo/ark-tx-builders/coinselect"
 )
 
 // UTXO represents an unspent transaction output
@@ -16,13 +18,26 @@ type UTXO struct {
 
 // BoardingTxParams contains parameters for building a boarding transaction
 type BoardingTxParams struct {
-	FundingUTXO    *UTXO
+	FundingUTXO *UTXO
+
+	// FundingUTXOs funds the boarding tx from a set of candidate UTXOs
+	// instead of a single preselected one: BuildBoardingTx runs coin
+	// selection (see coinselect.Select) to pick the subset that covers
+	// Amount plus fees, using FundingStrategy. Mutually exclusive with
+	// FundingUTXO; exactly one of the two must be set.
+	FundingUTXOs []*UTXO
+	// FundingStrategy selects which coinselect.Strategy FundingUTXOs is
+	// chosen with. Ignored when FundingUTXO is set. Zero value is
+	// coinselect.BranchAndBound.
+	FundingStrategy coinselect.Strategy
+
 	Amount         int64
 	UserPubKey     *btcec.PublicKey
 	OperatorPubKey *btcec.PublicKey
 	TimeoutBlocks  uint16
 	ChangeAddress  string // Optional, for change output
 	FeeRate        int64  // satoshis per vbyte
+	Memo           []byte // Optional, up to MaxMemoSize bytes, attached as a trailing OP_RETURN output
 }
 
 // CommitmentTxParams contains parameters for building a commitment transaction
@@ -35,6 +50,31 @@ type CommitmentTxParams struct {
 	UserPubKeys     []*btcec.PublicKey
 	BatchExpiry     uint32 // Absolute lock time
 	FeeRate         int64
+	Memo            []byte // Optional, up to MaxMemoSize bytes, attached as a trailing OP_RETURN output
+
+	// OperatorUTXOStrategy selects which coinselect.Strategy
+	// BuildCommitmentTx uses when OperatorUTXOs carries more than is
+	// needed to cover BatchAmount, ConnectorAmount and fees: rather than
+	// spending every operator UTXO passed in and burning the rest as
+	// fee, it selects only the subset required. Zero value is
+	// coinselect.BranchAndBound.
+	OperatorUTXOStrategy coinselect.Strategy
+
+	// UseEphemeralAnchor appends a zero-value P2A output and switches the
+	// transaction to TRUC (version 3), so a third party can CPFP-bump the
+	// commitment tx's fee via BuildAnchorSpend; the commitment tx's own
+	// fee is still sized at the full FeeRate. Deprecated: shorthand
+	// for AnchorPolicy: AnchorEphemeral, kept for backward compatibility;
+	// an explicit AnchorPolicy takes precedence over this field.
+	UseEphemeralAnchor bool
+
+	// AnchorPolicy selects the commitment tx's trailing CPFP handle: see
+	// AnchorPolicy in anchor.go. Zero value (AnchorNone) keeps
+	// pre-AnchorPolicy behavior, unless UseEphemeralAnchor is set.
+	AnchorPolicy AnchorPolicy
+	// BumpKey is the key an AnchorKeyed anchor output pays to. Required
+	// when AnchorPolicy is AnchorKeyed, ignored otherwise.
+	BumpKey *btcec.PublicKey
 }
 
 // ForfeitTxParams contains parameters for building a forfeit transaction
@@ -43,20 +83,106 @@ type ForfeitTxParams struct {
 	ConnectorAnchor *UTXO
 	OperatorPubKey  *btcec.PublicKey
 	FeeRate         int64
+	Memo            []byte // Optional, up to MaxMemoSize bytes, attached as a trailing OP_RETURN output
+
+	// VTXOUserPubKey and VTXOExpiry describe the VTXO leaf this forfeit
+	// spends (see VTXOLeaf and vtxo_tree.go), so BuildForfeitPSBT can
+	// reconstruct its Taproot script tree. Both are optional and unused
+	// by BuildForfeitTx, which only needs the VTXO's outpoint and amount.
+	VTXOUserPubKey *btcec.PublicKey
+	VTXOExpiry     uint32
+
+	// UseEphemeralAnchor appends a zero-value P2A output and switches the
+	// transaction to TRUC (version 3); see CommitmentTxParams.UseEphemeralAnchor.
+	// Deprecated: shorthand for AnchorPolicy: AnchorEphemeral.
+	UseEphemeralAnchor bool
+
+	// AnchorPolicy and BumpKey select the forfeit tx's trailing CPFP
+	// handle; see CommitmentTxParams.AnchorPolicy.
+	AnchorPolicy AnchorPolicy
+	BumpKey      *btcec.PublicKey
+}
+
+// UnilateralExitParams contains parameters for building a unilateral exit
+// (redeem) transaction: a user spending their own VTXO tree leaf via its
+// CSV timeout path once the operator is unresponsive past VTXOExpiry.
+type UnilateralExitParams struct {
+	VTXO               *UTXO
+	VTXOTreePath       []*wire.MsgTx // Pre-signed chain from the batch root down to VTXO's own outpoint, in broadcast order
+	UserPrivKey        *btcec.PrivateKey
+	DestinationAddress string
+	FeeRate            int64
+	CurrentHeight      uint32
+
+	// VTXOTree and LeafIndex identify the VTXO leaf within its tree (see
+	// VTXOLeaf and vtxo_tree.go), so BuildUnilateralExitTx can derive the
+	// leaf's expiry, CSV timeout script and control block via
+	// VTXOTree.LeafSpendInfo instead of having them passed in by hand.
+	VTXOTree  *VTXOTree
+	LeafIndex int
+}
+
+// VTXOTreeParams contains parameters for building a VTXO tree rooted at
+// a commitment transaction's batch output (see BuildVTXOTree).
+type VTXOTreeParams struct {
+	// BatchOutpoint is the outpoint the tree's root node spends: index 0
+	// of the commitment transaction, by convention.
+	BatchOutpoint wire.OutPoint
+	Leaves        []*VTXOLeaf
+
+	OperatorPubKey *btcec.PublicKey
+	BatchExpiry    uint32 // Absolute lock time
+
+	// RadixFanout is the number of children each internal node splits
+	// into. Zero defaults to 2 (a binary tree).
+	RadixFanout int
+
+	// BatchAmount, if set, is validated against the sum of every leaf's
+	// Amount: the tree must account for the commitment batch output
+	// exactly, with nothing left unclaimed or overcommitted. Leave zero
+	// to skip the check.
+	BatchAmount int64
+
+	// AnchorPolicy selects the trailing CPFP handle each internal node's
+	// spend transaction carries. Unlike CommitmentTxParams/ForfeitTxParams,
+	// the zero value (AnchorNone) maps to AnchorEphemeral rather than no
+	// anchor, preserving the tree's pre-AnchorPolicy behavior of always
+	// carrying one, since the operator needs an unconditional handle to
+	// unilaterally rebroadcast any subtree. AnchorKeyed is rejected by
+	// BuildVTXOTree: a node's spend transaction has no fee slack to draw
+	// a real-valued anchor output from (its single input splits evenly
+	// across its children's outputs with nothing left over).
+	AnchorPolicy AnchorPolicy
+	// BumpKey is unused while AnchorKeyed is unsupported for tree nodes;
+	// reserved for when a node has slack to carry one.
+	BumpKey *btcec.PublicKey
 }
 
 // TxBuilder provides methods to build deterministic transactions
-type TxBuilder struct{}
+type TxBuilder struct {
+	// CanonicalOrdering controls whether BuildBoardingTx, BuildCommitmentTx,
+	// and BuildUnilateralExitTx sort their inputs/outputs into BIP-69
+	// canonical order (see bip69.go). Defaults to true via NewTxBuilder, so
+	// that two operators building the same commitment from the same inputs
+	// produce byte-identical unsigned transactions. Set to false only when
+	// a caller needs to preserve its own input/output ordering verbatim.
+	CanonicalOrdering bool
+}
 
-// NewTxBuilder creates a new TxBuilder instance
+// NewTxBuilder creates a new TxBuilder instance with CanonicalOrdering
+// enabled.
 func NewTxBuilder() *TxBuilder {
-	return &TxBuilder{}
+	return &TxBuilder{CanonicalOrdering: true}
 }
 
 const (
 	// Transaction version
 	TxVersion = 2
 
+	// TxVersionTRUC is the BIP-431 TRUC (v3) version required by Bitcoin
+	// Core 28 package relay for transactions carrying an ephemeral anchor.
+	TxVersionTRUC = 3
+
 	// Sequence numbers
 	SequenceBoardingTx   = 0xFFFFFFFD
 	SequenceCommitmentTx = 0xFFFFFFFF
@@ -0,0 +1,147 @@
+package arkbuilders
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// unilateralExitSighashType mirrors forfeit.go's GetSighashType: binding
+// the exit spend to a specific destination with SIGHASH_ALL, rather than
+// relying on BIP-341's SIGHASH_DEFAULT.
+const unilateralExitSighashType = txscript.SigHashAll
+
+// BuildUnilateralExitTx creates a unilateral exit (redeem) transaction
+// letting a user spend their own VTXO tree leaf via its CSV timeout path
+// when the operator is unresponsive. Returns a deterministic transaction
+// with:
+// - Input: the VTXO outpoint, nSequence set to the leaf's CSV exit delay
+// - Output: single P2TR/P2WPKH/etc. payment to DestinationAddress
+// - Witness: script-path spend of the leaf's user-only timeout script
+func (tb *TxBuilder) BuildUnilateralExitTx(params *UnilateralExitParams) (*wire.MsgTx, error) {
+	// Validate parameters
+	if params.VTXO == nil {
+		return nil, errors.New("VTXO is required")
+	}
+	if params.VTXO.Amount <= 0 {
+		return nil, errors.New("VTXO amount must be positive")
+	}
+	if params.UserPrivKey == nil {
+		return nil, errors.New("user private key is required")
+	}
+	if params.VTXOTree == nil {
+		return nil, errors.New("VTXO tree is required")
+	}
+	if params.DestinationAddress == "" {
+		return nil, errors.New("destination address is required")
+	}
+	if params.FeeRate < MinFeeRate {
+		params.FeeRate = MinFeeRate
+	}
+
+	leaf := params.VTXOTree.findLeaf(params.VTXOTree.root, params.LeafIndex)
+	if leaf == nil {
+		return nil, errors.New("leaf index not found in VTXO tree")
+	}
+	if params.CurrentHeight < leaf.leafExpiry {
+		return nil, errors.New("current height has not reached the VTXO's expiry")
+	}
+
+	vtxoOutpoint := *wire.NewOutPoint(&params.VTXO.TxHash, params.VTXO.OutputIndex)
+	if err := verifyVTXOTreePath(params.VTXOTreePath, vtxoOutpoint, params.VTXOTree.RootOutpoint()); err != nil {
+		return nil, err
+	}
+
+	info, timeoutScript, err := params.VTXOTree.LeafSpendInfo(params.LeafIndex)
+	if err != nil {
+		return nil, err
+	}
+	controlBlock, err := info.ControlBlock(timeoutScript)
+	if err != nil {
+		return nil, err
+	}
+
+	destAddr, err := btcutil.DecodeAddress(params.DestinationAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+	destScript, err := txscript.PayToAddrScript(destAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create new transaction with deterministic fields
+	tx := newDeterministicTx(TxVersion, 0)
+
+	// Add VTXO input, sequence set to the leaf's own CSV exit delay
+	txIn := wire.NewTxIn(&vtxoOutpoint, nil, nil)
+	txIn.Sequence = uint32(leaf.leafExitDelay)
+	tx.AddTxIn(txIn)
+
+	// Calculate output amount (input - fee)
+	estimatedSize := estimateTxSize(tx, 1, len(timeoutScript)+len(controlBlock)+66)
+	fee := estimatedSize * params.FeeRate
+
+	outputAmount := params.VTXO.Amount - fee
+	if outputAmount < DustLimit {
+		return nil, errors.New("insufficient VTXO amount to cover fees")
+	}
+
+	tx.AddTxOut(wire.NewTxOut(outputAmount, destScript))
+	if tb.CanonicalOrdering {
+		sortTxOutputs(tx)
+	}
+
+	// Sign the script-path spend
+	prevFetcher := txscript.NewCannedPrevOutputFetcher(params.VTXO.ScriptPubKey, params.VTXO.Amount)
+	sigHashes := txscript.NewTxSigHashes(tx, prevFetcher)
+	tapLeaf := txscript.NewBaseTapLeaf(timeoutScript)
+	sigHash, err := txscript.CalcTapscriptSignaturehash(sigHashes, unilateralExitSighashType, tx, 0, prevFetcher, tapLeaf)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := schnorr.Sign(params.UserPrivKey, sigHash)
+	if err != nil {
+		return nil, err
+	}
+	sig := signature.Serialize()
+	if unilateralExitSighashType != txscript.SigHashDefault {
+		sig = append(sig, byte(unilateralExitSighashType))
+	}
+
+	tx.TxIn[0].Witness = AssembleTaprootScriptWitness([][]byte{sig}, timeoutScript, controlBlock)
+
+	return tx, nil
+}
+
+// verifyVTXOTreePath checks that path is a chain of transactions in
+// broadcast order terminating at vtxoOutpoint, mirroring the chain check
+// TestVTXOTreePathTo already performs on VTXOTree.PathTo's output. A
+// zero-length path is only valid when vtxoOutpoint is itself
+// batchOutpoint: VTXOTree.buildSpendTxs never builds a spend transaction
+// for a single-leaf tree's root (the root IS the leaf), so PathTo
+// correctly returns an empty path for that case rather than an error.
+func verifyVTXOTreePath(path []*wire.MsgTx, vtxoOutpoint, batchOutpoint wire.OutPoint) error {
+	if len(path) == 0 {
+		if vtxoOutpoint != batchOutpoint {
+			return errors.New("VTXO tree path is required")
+		}
+		return nil
+	}
+
+	for i := 1; i < len(path); i++ {
+		if path[i].TxIn[0].PreviousOutPoint.Hash != path[i-1].TxHash() {
+			return errors.New("VTXO tree path is not in broadcast order")
+		}
+	}
+
+	if path[len(path)-1].TxHash() != vtxoOutpoint.Hash {
+		return errors.New("VTXO tree path does not terminate at the VTXO's outpoint")
+	}
+
+	return nil
+}
@@ -0,0 +1,485 @@
+package arkbuilders
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// defaultVTXOTreeRadixFanout is the number of children each internal
+// node pairs into when VTXOTreeParams.RadixFanout is left unset.
+const defaultVTXOTreeRadixFanout = 2
+
+// VTXOLeaf is one leaf of a VTXO tree: a single user's share of a
+// commitment batch, with its own absolute expiry after which the
+// operator may unilaterally sweep it, and a relative CSV delay after
+// which the user may unilaterally exit via BuildUnilateralExitTx if the
+// operator is unresponsive.
+type VTXOLeaf struct {
+	UserPubKey *btcec.PublicKey
+	Amount     int64
+	Expiry     uint32
+	ExitDelay  uint16
+}
+
+// LeafRef identifies one leaf of a built VTXOTree by its outpoint, for
+// callers that only need to enumerate the tree's leaves rather than walk
+// it (see VTXOTree.Leaves).
+type LeafRef struct {
+	UserPubKey *btcec.PublicKey
+	Amount     int64
+	Outpoint   wire.OutPoint
+}
+
+// VTXOTree is the N-ary tree of pre-signable transactions committing to
+// a BuildCommitmentTx batch output: each internal node is a 2-of-2
+// MuSig2(operator, aggregated subtree users) Taproot output, and each
+// node's spend transaction pays out to its children plus a dedicated
+// ephemeral anchor output (BuildVTXOTree rejects AnchorKeyed for tree
+// nodes), so the operator can unilaterally
+// broadcast any subtree without cooperation from users outside it, and
+// fees for that broadcast are bumped via the anchor rather than drawn
+// from the children's own amounts.
+type VTXOTree struct {
+	root           *vtxoTreeNode
+	operatorPubKey *btcec.PublicKey
+	batchExpiry    uint32
+	radixFanout    int
+	anchorPolicy   AnchorPolicy
+	bumpKey        *btcec.PublicKey
+
+	levels        [][]*wire.MsgTx
+	leafOutpoints []wire.OutPoint
+	nodes         map[wire.OutPoint]*wire.MsgTx
+}
+
+// vtxoTreeNode is one node of the tree built bottom-up from the leaf
+// set. Internal nodes carry the MuSig2 aggregate of every user key under
+// them; leaves carry a single user's key. spendTx is the transaction
+// that spends this node's own Taproot output to produce its children's
+// outputs (nil for leaves, which have nothing further to spend into).
+type vtxoTreeNode struct {
+	userAggKey *btcec.PublicKey
+	amount     int64
+	scriptKey  *btcec.PublicKey // MuSig2(operator, userAggKey)
+
+	isLeaf        bool
+	leafIndex     int
+	leafExpiry    uint32
+	leafExitDelay uint16
+
+	children []*vtxoTreeNode
+	parent   *vtxoTreeNode
+
+	outpoint wire.OutPoint
+	spendTx  *wire.MsgTx
+}
+
+// BuildVTXOTree constructs the full VTXO tree rooted at
+// params.BatchOutpoint (the commitment tx's batch output). Leaves are
+// sorted by (userPubKey, amount) before partitioning into subtrees of
+// params.RadixFanout (default 2) so the same leaf set in any input order
+// yields an identical tree; a partial group left over at the end of a
+// level is promoted unchanged to the next level up, mirroring
+// buildTapTreeWithPaths.
+func BuildVTXOTree(params *VTXOTreeParams) (*VTXOTree, error) {
+	if params == nil {
+		return nil, errors.New("VTXO tree parameters are required")
+	}
+	if len(params.Leaves) == 0 {
+		return nil, errors.New("at least one VTXO leaf is required")
+	}
+	if params.OperatorPubKey == nil {
+		return nil, errors.New("operator public key is required")
+	}
+	if params.AnchorPolicy == AnchorKeyed {
+		// Unlike CommitmentTxParams/ForfeitTxParams, a node's spend
+		// transaction has no fee slack to draw a real-valued anchor
+		// output from: its single input (the node's own amount) is
+		// split evenly across its children's outputs with nothing left
+		// over, so a keyed anchor's DustLimit value would always push
+		// total outputs past the node's own input.
+		return nil, errors.New("AnchorKeyed is not supported for VTXO tree nodes; use AnchorEphemeral")
+	}
+
+	radixFanout := params.RadixFanout
+	if radixFanout <= 0 {
+		radixFanout = defaultVTXOTreeRadixFanout
+	}
+
+	var leafAmountSum int64
+	type indexedLeaf struct {
+		leaf  *VTXOLeaf
+		index int
+	}
+	sorted := make([]indexedLeaf, len(params.Leaves))
+	for i, leaf := range params.Leaves {
+		if leaf == nil || leaf.UserPubKey == nil {
+			return nil, errors.New("every VTXO leaf requires a user public key")
+		}
+		if leaf.Amount <= 0 {
+			return nil, errors.New("every VTXO leaf amount must be positive")
+		}
+		leafAmountSum += leaf.Amount
+		sorted[i] = indexedLeaf{leaf: leaf, index: i}
+	}
+	if params.BatchAmount > 0 && leafAmountSum != params.BatchAmount {
+		return nil, errors.New("sum of VTXO leaf amounts does not equal the commitment batch amount")
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		cmp := bytes.Compare(
+			schnorr.SerializePubKey(sorted[i].leaf.UserPubKey),
+			schnorr.SerializePubKey(sorted[j].leaf.UserPubKey),
+		)
+		if cmp != 0 {
+			return cmp < 0
+		}
+		return sorted[i].leaf.Amount < sorted[j].leaf.Amount
+	})
+
+	nodes := make([]*vtxoTreeNode, len(sorted))
+	for i, il := range sorted {
+		nodes[i] = &vtxoTreeNode{
+			userAggKey:    il.leaf.UserPubKey,
+			amount:        il.leaf.Amount,
+			isLeaf:        true,
+			leafIndex:     il.index,
+			leafExpiry:    il.leaf.Expiry,
+			leafExitDelay: il.leaf.ExitDelay,
+		}
+	}
+
+	for len(nodes) > 1 {
+		var next []*vtxoTreeNode
+		for i := 0; i < len(nodes); i += radixFanout {
+			end := i + radixFanout
+			if end > len(nodes) {
+				end = len(nodes)
+			}
+			group := nodes[i:end]
+			if len(group) == 1 {
+				next = append(next, group[0])
+				continue
+			}
+
+			keys := make([]*btcec.PublicKey, len(group))
+			var amount int64
+			for j, child := range group {
+				keys[j] = child.userAggKey
+				amount += child.amount
+			}
+			aggKey, err := MuSig2AggregateKeys(keys...)
+			if err != nil {
+				return nil, err
+			}
+
+			parent := &vtxoTreeNode{
+				userAggKey: aggKey,
+				amount:     amount,
+				children:   group,
+			}
+			for _, child := range group {
+				child.parent = parent
+			}
+			next = append(next, parent)
+		}
+		nodes = next
+	}
+
+	anchorPolicy := params.AnchorPolicy
+	if anchorPolicy == AnchorNone {
+		anchorPolicy = AnchorEphemeral
+	}
+
+	tree := &VTXOTree{
+		root:           nodes[0],
+		operatorPubKey: params.OperatorPubKey,
+		batchExpiry:    params.BatchExpiry,
+		radixFanout:    radixFanout,
+		anchorPolicy:   anchorPolicy,
+		bumpKey:        params.BumpKey,
+		leafOutpoints:  make([]wire.OutPoint, len(params.Leaves)),
+		nodes:          make(map[wire.OutPoint]*wire.MsgTx),
+	}
+
+	if err := tree.assignScriptKeys(tree.root); err != nil {
+		return nil, err
+	}
+	if err := tree.buildSpendTxs(tree.root, params.BatchOutpoint); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// assignScriptKeys computes each node's 2-of-2 MuSig2(operator, userAggKey)
+// key, which only depends on the node's own data and can be filled in
+// before any transaction is built.
+func (t *VTXOTree) assignScriptKeys(node *vtxoTreeNode) error {
+	scriptKey, err := MuSig2AggregateKeys(t.operatorPubKey, node.userAggKey)
+	if err != nil {
+		return err
+	}
+	node.scriptKey = scriptKey
+
+	if node.isLeaf {
+		return nil
+	}
+	for _, child := range node.children {
+		if err := t.assignScriptKeys(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeTimelockExpiry is the absolute locktime used for a node's operator
+// sweep path: a leaf's own expiry for leaves, the batch expiry for
+// internal nodes.
+func (t *VTXOTree) nodeTimelockExpiry(node *vtxoTreeNode) uint32 {
+	if node.isLeaf {
+		return node.leafExpiry
+	}
+	return t.batchExpiry
+}
+
+// nodeLeafScripts returns node's tapscript leaves: an operator-only sweep
+// path after its expiry, matching the sweep-plus-cooperative-path shape
+// BuildCommitmentTx already uses for the batch output, plus, for a VTXO
+// leaf only, a user-only CSV timeout path so the user can unilaterally
+// exit via BuildUnilateralExitTx if the operator is unresponsive.
+func (t *VTXOTree) nodeLeafScripts(node *vtxoTreeNode) ([][]byte, error) {
+	sweepScript, err := BuildCheckSigWithAbsTimelockScript(t.operatorPubKey, t.nodeTimelockExpiry(node))
+	if err != nil {
+		return nil, err
+	}
+	if !node.isLeaf {
+		return [][]byte{sweepScript}, nil
+	}
+
+	timeoutScript, err := BuildCheckSigWithTimelockScript(node.userAggKey, node.leafExitDelay)
+	if err != nil {
+		return nil, err
+	}
+	return sortScripts([][]byte{sweepScript, timeoutScript}), nil
+}
+
+// nodeSpendInfo builds the full TaprootSpendInfo for node, so both
+// nodeScriptPubKey and LeafSpendInfo derive control blocks from the same
+// tree.
+func (t *VTXOTree) nodeSpendInfo(node *vtxoTreeNode) (*TaprootSpendInfo, error) {
+	scripts, err := t.nodeLeafScripts(node)
+	if err != nil {
+		return nil, err
+	}
+	return NewTaprootSpendInfo(node.scriptKey, scripts)
+}
+
+// nodeScriptPubKey builds the Taproot output script for node.
+func (t *VTXOTree) nodeScriptPubKey(node *vtxoTreeNode) ([]byte, error) {
+	info, err := t.nodeSpendInfo(node)
+	if err != nil {
+		return nil, err
+	}
+	return info.ScriptPubKey(), nil
+}
+
+// LeafSpendInfo returns leafIndex's own TaprootSpendInfo along with its
+// user-only CSV timeout script, so BuildUnilateralExitTx can assemble a
+// script-path witness without recomputing the tree.
+func (t *VTXOTree) LeafSpendInfo(leafIndex int) (*TaprootSpendInfo, []byte, error) {
+	leaf := t.findLeaf(t.root, leafIndex)
+	if leaf == nil {
+		return nil, nil, errors.New("leaf index not found in tree")
+	}
+
+	timeoutScript, err := BuildCheckSigWithTimelockScript(leaf.userAggKey, leaf.leafExitDelay)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := t.nodeSpendInfo(leaf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return info, timeoutScript, nil
+}
+
+// buildSpendTxs walks the tree top-down, building each internal node's
+// spend transaction once its own outpoint is known (the root's outpoint
+// is the tree's batch outpoint; every other node's outpoint is set by
+// its parent's spend transaction). Each spend transaction carries one
+// output per child plus a trailing anchor output (see t.anchorPolicy),
+// so the node's own amount splits evenly across its children with no
+// per-node fee draw, and broadcasting that node's subtree is bumped via
+// the anchor instead.
+func (t *VTXOTree) buildSpendTxs(node *vtxoTreeNode, outpoint wire.OutPoint) error {
+	node.outpoint = outpoint
+
+	if node.isLeaf {
+		t.leafOutpoints[node.leafIndex] = outpoint
+		return nil
+	}
+
+	type childOut struct {
+		node *vtxoTreeNode
+		out  *wire.TxOut
+	}
+	pairs := make([]childOut, len(node.children))
+	for i, child := range node.children {
+		script, err := t.nodeScriptPubKey(child)
+		if err != nil {
+			return err
+		}
+		pairs[i] = childOut{node: child, out: wire.NewTxOut(child.amount, script)}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].out.Value != pairs[j].out.Value {
+			return pairs[i].out.Value < pairs[j].out.Value
+		}
+		return bytes.Compare(pairs[i].out.PkScript, pairs[j].out.PkScript) < 0
+	})
+
+	tx := newDeterministicTx(TxVersionTRUC, 0)
+
+	txIn := wire.NewTxIn(&outpoint, nil, nil)
+	txIn.Sequence = wire.MaxTxInSequenceNum
+	tx.AddTxIn(txIn)
+
+	for _, pair := range pairs {
+		tx.AddTxOut(pair.out)
+	}
+	anchorOutput, err := buildAnchorOutput(t.anchorPolicy, t.bumpKey)
+	if err != nil {
+		return err
+	}
+	tx.AddTxOut(anchorOutput)
+
+	node.spendTx = tx
+	level := t.nodeDepth(node)
+	for len(t.levels) <= level {
+		t.levels = append(t.levels, nil)
+	}
+	t.levels[level] = append(t.levels[level], tx)
+
+	txHash := tx.TxHash()
+	t.nodes[outpoint] = tx
+	for i, pair := range pairs {
+		if err := t.buildSpendTxs(pair.node, *wire.NewOutPoint(&txHash, uint32(i))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nodeDepth returns the root-relative depth of node (0 for the root).
+func (t *VTXOTree) nodeDepth(node *vtxoTreeNode) int {
+	depth := 0
+	for n := node; n.parent != nil; n = n.parent {
+		depth++
+	}
+	return depth
+}
+
+// Root returns the batch output this tree commits to: the root node's
+// Taproot output script and aggregate amount.
+func (t *VTXOTree) Root() (*wire.TxOut, error) {
+	script, err := t.nodeScriptPubKey(t.root)
+	if err != nil {
+		return nil, err
+	}
+	return wire.NewTxOut(t.root.amount, script), nil
+}
+
+// RootOutpoint returns the batch outpoint the tree was built from.
+func (t *VTXOTree) RootOutpoint() wire.OutPoint {
+	return t.root.outpoint
+}
+
+// Nodes returns every internal node's spend transaction keyed by the
+// outpoint it spends, so a caller can look up or rebroadcast any subtree
+// without walking the tree by level.
+func (t *VTXOTree) Nodes() map[wire.OutPoint]*wire.MsgTx {
+	nodes := make(map[wire.OutPoint]*wire.MsgTx, len(t.nodes))
+	for outpoint, tx := range t.nodes {
+		nodes[outpoint] = tx
+	}
+	return nodes
+}
+
+// Leaves returns a LeafRef for every leaf in the tree, in leaf-index
+// order.
+func (t *VTXOTree) Leaves() []LeafRef {
+	refs := make([]LeafRef, len(t.leafOutpoints))
+	t.collectLeaves(t.root, refs)
+	return refs
+}
+
+func (t *VTXOTree) collectLeaves(node *vtxoTreeNode, refs []LeafRef) {
+	if node.isLeaf {
+		refs[node.leafIndex] = LeafRef{
+			UserPubKey: node.userAggKey,
+			Amount:     node.amount,
+			Outpoint:   node.outpoint,
+		}
+		return
+	}
+	for _, child := range node.children {
+		t.collectLeaves(child, refs)
+	}
+}
+
+// TxsAtLevel returns every spend transaction at depth i (0 = the
+// transaction spending the batch outpoint directly).
+func (t *VTXOTree) TxsAtLevel(i int) []*wire.MsgTx {
+	if i < 0 || i >= len(t.levels) {
+		return nil
+	}
+	return t.levels[i]
+}
+
+// PathTo returns the chain of pre-signed transactions from the batch
+// outpoint down to (but not including) the spend of leafIndex's own
+// output, in broadcast order.
+func (t *VTXOTree) PathTo(leafIndex int) ([]*wire.MsgTx, error) {
+	if leafIndex < 0 || leafIndex >= len(t.leafOutpoints) {
+		return nil, errors.New("leaf index out of range")
+	}
+
+	leaf := t.findLeaf(t.root, leafIndex)
+	if leaf == nil {
+		return nil, errors.New("leaf index not found in tree")
+	}
+
+	var path []*wire.MsgTx
+	for n := leaf.parent; n != nil; n = n.parent {
+		path = append(path, n.spendTx)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+func (t *VTXOTree) findLeaf(node *vtxoTreeNode, leafIndex int) *vtxoTreeNode {
+	if node == nil {
+		return nil
+	}
+	if node.isLeaf {
+		if node.leafIndex == leafIndex {
+			return node
+		}
+		return nil
+	}
+	for _, child := range node.children {
+		if found := t.findLeaf(child, leafIndex); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package arkbuilders
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVTXOTreeDeterminism verifies that the same leaf set in any input
+// order produces a tree with an identical root txid, mirroring
+// TestCommitmentInputOrdering's input-order independence check.
+func TestVTXOTreeDeterminism(t *testing.T) {
+	operatorPrivKey := createTestPrivKey(t, 0x10)
+	batchOutpoint := createTestUTXO(400000, 0)
+	outpoint := *wire.NewOutPoint(&batchOutpoint.TxHash, batchOutpoint.OutputIndex)
+
+	leaves := []*VTXOLeaf{
+		{UserPubKey: createTestPrivKey(t, 0x01).PubKey(), Amount: 100000, Expiry: 500000},
+		{UserPubKey: createTestPrivKey(t, 0x02).PubKey(), Amount: 100000, Expiry: 500001},
+		{UserPubKey: createTestPrivKey(t, 0x03).PubKey(), Amount: 100000, Expiry: 500002},
+		{UserPubKey: createTestPrivKey(t, 0x04).PubKey(), Amount: 100000, Expiry: 500003},
+	}
+
+	tree, err := BuildVTXOTree(&VTXOTreeParams{
+		BatchOutpoint:  outpoint,
+		Leaves:         leaves,
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		BatchExpiry:    600000,
+		BatchAmount:    400000,
+	})
+	require.NoError(t, err)
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		shuffled := make([]*VTXOLeaf, len(leaves))
+		copy(shuffled, leaves)
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		shuffledTree, err := BuildVTXOTree(&VTXOTreeParams{
+			BatchOutpoint:  outpoint,
+			Leaves:         shuffled,
+			OperatorPubKey: operatorPrivKey.PubKey(),
+			BatchExpiry:    600000,
+			BatchAmount:    400000,
+		})
+		require.NoError(t, err)
+		shuffledRoot, err := shuffledTree.Root()
+		require.NoError(t, err)
+
+		require.Equal(t, root.PkScript, shuffledRoot.PkScript)
+		require.Equal(t, root.Value, shuffledRoot.Value)
+
+		for level := 0; level < 2; level++ {
+			txs := shuffledTree.TxsAtLevel(level)
+			wantTxs := tree.TxsAtLevel(level)
+			require.NotEmpty(t, txs)
+			// BuildVTXOTree sorts leaves by (userPubKey, amount) before
+			// partitioning into subtrees, so the same leaf set always
+			// produces the same tree shape, in the same per-level
+			// order, regardless of the input order: match each shuffled
+			// tx against its corresponding unshuffled tx by position,
+			// not a single fixed index.
+			require.Len(t, txs, len(wantTxs))
+			for i, tx := range txs {
+				require.Equal(t, wantTxs[i].TxHash(), tx.TxHash())
+			}
+		}
+	}
+}
+
+// TestVTXOTreePathTo verifies that the path from the batch outpoint to a
+// leaf is a non-empty chain of transactions starting at the batch
+// outpoint, where each spends an output of the previous one.
+func TestVTXOTreePathTo(t *testing.T) {
+	operatorPrivKey := createTestPrivKey(t, 0x10)
+	batchOutpoint := createTestUTXO(400000, 0)
+	outpoint := *wire.NewOutPoint(&batchOutpoint.TxHash, batchOutpoint.OutputIndex)
+
+	leaves := []*VTXOLeaf{
+		{UserPubKey: createTestPrivKey(t, 0x01).PubKey(), Amount: 100000, Expiry: 500000},
+		{UserPubKey: createTestPrivKey(t, 0x02).PubKey(), Amount: 100000, Expiry: 500001},
+		{UserPubKey: createTestPrivKey(t, 0x03).PubKey(), Amount: 100000, Expiry: 500002},
+	}
+
+	tree, err := BuildVTXOTree(&VTXOTreeParams{
+		BatchOutpoint:  outpoint,
+		Leaves:         leaves,
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		BatchExpiry:    600000,
+	})
+	require.NoError(t, err)
+
+	for i := range leaves {
+		path, err := tree.PathTo(i)
+		require.NoError(t, err)
+		require.NotEmpty(t, path)
+
+		require.Len(t, path[0].TxIn, 1)
+		require.Equal(t, outpoint, path[0].TxIn[0].PreviousOutPoint)
+
+		for k := 1; k < len(path); k++ {
+			require.Len(t, path[k].TxIn, 1)
+			require.Equal(t, path[k-1].TxHash(), path[k].TxIn[0].PreviousOutPoint.Hash)
+		}
+	}
+}
+
+// TestVTXOTreeRejectsEmptyLeaves verifies the constructor validates its
+// inputs instead of producing a degenerate tree.
+func TestVTXOTreeRejectsEmptyLeaves(t *testing.T) {
+	operatorPrivKey := createTestPrivKey(t, 0x10)
+	batchOutpoint := createTestUTXO(400000, 0)
+	outpoint := *wire.NewOutPoint(&batchOutpoint.TxHash, batchOutpoint.OutputIndex)
+
+	_, err := BuildVTXOTree(&VTXOTreeParams{
+		BatchOutpoint:  outpoint,
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		BatchExpiry:    600000,
+	})
+	require.Error(t, err)
+}
+
+// TestVTXOTreeRejectsBatchAmountMismatch verifies that a non-zero
+// BatchAmount is validated against the sum of leaf amounts.
+func TestVTXOTreeRejectsBatchAmountMismatch(t *testing.T) {
+	operatorPrivKey := createTestPrivKey(t, 0x10)
+	batchOutpoint := createTestUTXO(400000, 0)
+	outpoint := *wire.NewOutPoint(&batchOutpoint.TxHash, batchOutpoint.OutputIndex)
+
+	leaves := []*VTXOLeaf{
+		{UserPubKey: createTestPrivKey(t, 0x01).PubKey(), Amount: 100000, Expiry: 500000},
+		{UserPubKey: createTestPrivKey(t, 0x02).PubKey(), Amount: 100000, Expiry: 500001},
+	}
+
+	_, err := BuildVTXOTree(&VTXOTreeParams{
+		BatchOutpoint:  outpoint,
+		Leaves:         leaves,
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		BatchExpiry:    600000,
+		BatchAmount:    300000,
+	})
+	require.Error(t, err)
+}
+
+// TestVTXOTreeRadixFanout verifies that a non-default RadixFanout groups
+// more than two leaves under each internal node and still converges to a
+// single root.
+func TestVTXOTreeRadixFanout(t *testing.T) {
+	operatorPrivKey := createTestPrivKey(t, 0x10)
+	batchOutpoint := createTestUTXO(400000, 0)
+	outpoint := *wire.NewOutPoint(&batchOutpoint.TxHash, batchOutpoint.OutputIndex)
+
+	leaves := []*VTXOLeaf{
+		{UserPubKey: createTestPrivKey(t, 0x01).PubKey(), Amount: 100000, Expiry: 500000},
+		{UserPubKey: createTestPrivKey(t, 0x02).PubKey(), Amount: 100000, Expiry: 500001},
+		{UserPubKey: createTestPrivKey(t, 0x03).PubKey(), Amount: 100000, Expiry: 500002},
+		{UserPubKey: createTestPrivKey(t, 0x04).PubKey(), Amount: 100000, Expiry: 500003},
+	}
+
+	tree, err := BuildVTXOTree(&VTXOTreeParams{
+		BatchOutpoint:  outpoint,
+		Leaves:         leaves,
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		BatchExpiry:    600000,
+		RadixFanout:    4,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, tree.TxsAtLevel(0), 1)
+	require.Empty(t, tree.TxsAtLevel(1))
+
+	rootTx := tree.TxsAtLevel(0)[0]
+	require.Len(t, rootTx.TxOut, len(leaves)+1) // one per leaf, plus the trailing ephemeral anchor
+
+	nodes := tree.Nodes()
+	require.Len(t, nodes, 1)
+	require.Len(t, tree.Leaves(), len(leaves))
+}
+
+// TestVTXOTreeAnchorPolicy verifies that the zero-value AnchorPolicy
+// still defaults to an ephemeral anchor, and that AnchorKeyed is
+// rejected since a node's spend transaction has no fee slack to draw a
+// real-valued anchor output from.
+func TestVTXOTreeAnchorPolicy(t *testing.T) {
+	operatorPrivKey := createTestPrivKey(t, 0x10)
+	bumpPrivKey := createTestPrivKey(t, 0x20)
+	batchOutpoint := createTestUTXO(200000, 0)
+	outpoint := *wire.NewOutPoint(&batchOutpoint.TxHash, batchOutpoint.OutputIndex)
+
+	leaves := []*VTXOLeaf{
+		{UserPubKey: createTestPrivKey(t, 0x01).PubKey(), Amount: 100000, Expiry: 500000},
+		{UserPubKey: createTestPrivKey(t, 0x02).PubKey(), Amount: 100000, Expiry: 500001},
+	}
+
+	defaultTree, err := BuildVTXOTree(&VTXOTreeParams{
+		BatchOutpoint:  outpoint,
+		Leaves:         leaves,
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		BatchExpiry:    600000,
+	})
+	require.NoError(t, err)
+	rootTx := defaultTree.TxsAtLevel(0)[0]
+	lastOut := rootTx.TxOut[len(rootTx.TxOut)-1]
+	assert.Equal(t, int64(EphemeralAnchorAmount), lastOut.Value)
+	assert.Equal(t, ephemeralAnchorScript, lastOut.PkScript)
+
+	_, err = BuildVTXOTree(&VTXOTreeParams{
+		BatchOutpoint:  outpoint,
+		Leaves:         leaves,
+		OperatorPubKey: operatorPrivKey.PubKey(),
+		BatchExpiry:    600000,
+		AnchorPolicy:   AnchorKeyed,
+		BumpKey:        bumpPrivKey.PubKey(),
+	})
+	assert.Error(t, err, "AnchorKeyed is not supported for VTXO tree nodes")
+}